@@ -1,20 +1,33 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
-	"github.com/lewvy/gopk/cmd/internal/service"
+	"github.com/lewvy/gopk/pkg/gopk"
 	"github.com/spf13/cobra"
 )
 
 var addCmd = &cobra.Command{
-	Use:   "add <module-path>",
-	Short: "Save a Go module for quick reuse",
+	Use:          "add <module-path>",
+	Short:        "Save a Go module for quick reuse",
+	SilenceUsage: true,
 	Long: `Add a Go module to your gopk registry.
 
 The add command stores a module path under a human-friendly alias,
 allowing you to quickly recall and install it in future projects.
 
+The path may point at a package inside a module, such as
+golang.org/x/sync/errgroup. The module root is inferred for well-known
+hosts and can be set explicitly with --module; 'gopk get' fetches the
+module while the alias keeps the exact import path.
+
+Commands such as linters and code generators should be saved with
+--kind tool (or both, for modules that are also imported). 'gopk get'
+adds tools to the module with 'go get -tool', and 'gopk install'
+installs their binaries globally.
+
 By default, this command only records the module and does not modify
 the current project. Use --install to immediately run 'go get' for
 the added package in the current Go module.`,
@@ -25,23 +38,43 @@ the added package in the current Go module.`,
 		url := args[0]
 
 		name, _ := cmd.Flags().GetString("name")
+		module, _ := cmd.Flags().GetString("module")
+		importName, _ := cmd.Flags().GetString("import-name")
 		version, _ := cmd.Flags().GetString("version")
+		kind, _ := cmd.Flags().GetString("kind")
 		install, _ := cmd.Flags().GetBool("install")
 		force, _ := cmd.Flags().GetBool("force")
+		failOnVuln, _ := cmd.Flags().GetBool("fail-on-vuln")
 
-		err := service.Add(url, name, version, install, force, queries)
-		if err == service.ErrConstraintUnique {
-			return fmt.Errorf("package %s already exists. use --force to overwrite", name)
+		ctx := context.Background()
+		pkg, err := registry.Add(ctx, url, gopk.AddOptions{
+			Name:       name,
+			Module:     module,
+			ImportName: importName,
+			Version:    version,
+			Kind:       kind,
+			Force:      force,
+		})
+		if errors.Is(err, gopk.ErrExists) {
+			return fmt.Errorf("%w. use --force to overwrite", err)
 		}
-		return nil
+		if err != nil || !install {
+			return err
+		}
+
+		return registry.Install(ctx, []string{pkg.Name}, gopk.InstallOptions{FailOnVuln: failOnVuln})
 	},
 }
 
 func init() {
 	addCmd.Flags().StringP("name", "n", "", "add package name")
+	addCmd.Flags().StringP("module", "m", "", "module root of the package (inferred when empty)")
+	addCmd.Flags().String("import-name", "", "name to import the package as (e.g. zlog)")
 	addCmd.Flags().StringP("version", "v", "latest", "add package version (used for go installs)")
+	addCmd.Flags().StringP("kind", "k", "library", "package kind: library, tool or both")
 	addCmd.Flags().BoolP("install", "i", false, "install the package")
 	addCmd.Flags().BoolP("force", "f", false, "force add to registry")
+	addCmd.Flags().Bool("fail-on-vuln", false, "with --install, do not install versions with known vulnerabilities")
 
 	rootCmd.AddCommand(addCmd)
 
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+
+	"github.com/lewvy/gopk/internal/service"
+	"github.com/spf13/cobra"
+)
+
+var aliasCmd = &cobra.Command{
+	Use:          "alias [alias]",
+	Short:        "List the extra aliases of packages",
+	SilenceUsage: true,
+	Long: `Manage the extra aliases of saved packages.
+
+Every package is saved under one primary alias, set by 'gopk add', and
+can be given any number of extra aliases: 'gopk get', 'gopk install',
+'gopk search' and the other commands taking aliases resolve them to the
+same package. An alias belongs to a single package; adding one that is
+already taken reports the package that owns it.
+
+Without arguments, every extra alias is listed. With an alias, the extra
+aliases of that package are listed.
+
+Examples:
+  gopk alias add zap uberzap log
+  gopk alias zap
+  gopk alias rm log`,
+
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeAlias,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		var aliases []service.Alias
+		var err error
+		if len(args) == 1 {
+			pkg, names, err := service.ListAliases(ctx, queries, args[0])
+			if err != nil {
+				return err
+			}
+			for _, name := range names {
+				aliases = append(aliases, service.Alias{Name: name, Package: pkg})
+			}
+		} else if aliases, err = service.Aliases(ctx, queries); err != nil {
+			return err
+		}
+		if len(aliases) == 0 && tableOutput(cmd) {
+			cmd.Println("No extra aliases saved; add one with 'gopk alias add'.")
+			return nil
+		}
+
+		rows := make([]aliasRow, len(aliases))
+		for i, a := range aliases {
+			rows[i] = aliasRow{Alias: a.Name, Package: a.Package.Name, URL: a.Package.Url}
+		}
+		return writeListing(cmd, rows, []column[aliasRow]{
+			{"ALIAS", func(r aliasRow) string { return r.Alias }},
+			{"PACKAGE", func(r aliasRow) string { return r.Package }},
+			{"URL", func(r aliasRow) string { return r.URL }},
+		})
+	},
+}
+
+// aliasRow is an extra alias as listed by 'gopk alias'.
+type aliasRow struct {
+	Alias   string `json:"alias"`
+	Package string `json:"package"`
+	URL     string `json:"url"`
+}
+
+var aliasAddCmd = &cobra.Command{
+	Use:          "add <alias> <name...>",
+	Short:        "Give a package extra aliases",
+	SilenceUsage: true,
+	Long: `Give the package saved as alias the extra aliases names. Names the
+package already answers to are skipped. If a name belongs to another
+package, nothing is added and that package is reported.`,
+
+	Args: cobra.MinimumNArgs(2),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completeAlias(cmd, args, toComplete)
+	},
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		added, err := registry.AddAliases(context.Background(), args[0], args[1:]...)
+		if err != nil {
+			return err
+		}
+		if len(added) == 0 {
+			cmd.Printf("%s already answers to %s\n", args[0], strings.Join(args[1:], ", "))
+			return nil
+		}
+		cmd.Printf("%s now also answers to %s\n", args[0], strings.Join(added, ", "))
+		return nil
+	},
+}
+
+var aliasRmCmd = &cobra.Command{
+	Use:               "rm <name...>",
+	Short:             "Remove extra aliases",
+	SilenceUsage:      true,
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeExtraAliases,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := registry.RemoveAliases(context.Background(), args...); err != nil {
+			return err
+		}
+		cmd.Printf("Removed %s\n", strings.Join(args, ", "))
+		return nil
+	},
+}
+
+func init() {
+	aliasCmd.AddCommand(aliasAddCmd)
+	aliasCmd.AddCommand(aliasRmCmd)
+
+	rootCmd.AddCommand(aliasCmd)
+}
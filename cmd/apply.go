@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/lewvy/gopk/internal/service"
+	"github.com/spf13/cobra"
+)
+
+var applyCmd = &cobra.Command{
+	Use:          "apply [file]",
+	Short:        "Bring the registry in line with a gopkfile or an export",
+	SilenceUsage: true,
+	Long: `Apply a declarative gopkfile manifest, or a file written by 'gopk
+export', to the local registry.
+
+A gopkfile declares packages, versions, snippets and groups in the same
+format as 'gopk export' (TOML by default; a .json, .yaml or .yml
+extension selects another format). The file is given as an argument or
+with --file, and defaults to ./gopkfile. apply prints a plan of
+additions, updates and deletions and then carries it out, following the
+same alias rules as 'gopk add'.
+
+By default, entries that are not declared in the file are kept. Use
+--prune to delete them, so that the registry ends up matching the file.
+Templates, which every profile shares, are only pruned when the file has
+a templates key. With --dry-run, the plan is only printed. With --check,
+nothing is changed either and the command exits with an error if the
+registry has drifted from the file.
+
+Examples:
+  gopk export -f gopkfile
+  gopk apply
+  gopk apply backup.toml --dry-run
+  gopk apply team.yaml --prune`,
+
+	Args: cobra.MaximumNArgs(1),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, _ := cmd.Flags().GetString("file")
+		prune, _ := cmd.Flags().GetBool("prune")
+		check, _ := cmd.Flags().GetBool("check")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		if len(args) == 1 {
+			if cmd.Flags().Changed("file") {
+				return fmt.Errorf("give the file as an argument or with --file, not both")
+			}
+			path = args[0]
+		}
+
+		return applyFile(cmd, path, prune, check, dryRun)
+	},
+}
+
+// applyFile prints the plan that brings the registry in line with the
+// registry file at path and, unless check or dryRun is set, carries it out.
+func applyFile(cmd *cobra.Command, path string, prune, check, dryRun bool) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	f, err := service.DecodeRegistryFile(content, service.FormatFromPath(path))
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	ctx := context.Background()
+	plan, err := service.PlanImport(ctx, queries, f, prune)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if len(plan.Changes) == 0 {
+		fmt.Fprintf(out, "Registry matches %s.\n", path)
+		return nil
+	}
+	for _, c := range plan.Changes {
+		fmt.Fprintln(out, c)
+	}
+	fmt.Fprintf(out, "\nPlan: %s.\n", plan.Summary())
+
+	if check {
+		return fmt.Errorf("registry has drifted from %s", path)
+	}
+	if dryRun {
+		return nil
+	}
+
+	if err := plan.Apply(ctx, queries); err != nil {
+		return err
+	}
+	if n := len(plan.Conflicts()); n > 0 {
+		return fmt.Errorf("skipped %d conflicting package(s)", n)
+	}
+	return nil
+}
+
+func init() {
+	applyCmd.Flags().StringP("file", "f", "gopkfile", "path to the gopkfile")
+	applyCmd.Flags().Bool("prune", false, "delete entries that are not declared in the gopkfile")
+	applyCmd.Flags().Bool("dry-run", false, "print the plan without applying it")
+	applyCmd.Flags().Bool("check", false, "exit with an error if the registry differs from the gopkfile, without changing it")
+
+	rootCmd.AddCommand(applyCmd)
+}
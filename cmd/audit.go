@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/lewvy/gopk/config"
+	"github.com/lewvy/gopk/internal/service"
+	"github.com/spf13/cobra"
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Check the current module against your policies",
+}
+
+var auditLicensesCmd = &cobra.Command{
+	Use:          "licenses",
+	Short:        "Check the licenses of every dependency in go.mod",
+	SilenceUsage: true,
+	Long: `Classify the license of every module required by the current go.mod and
+check it against the license policy in your config file.
+
+Licenses are read from the module sources in $GOMODCACHE; modules that
+are not cached are reported as unknown (run 'go mod download' first).
+The command exits with an error if any dependency is denied, or if a
+license is unknown and block_unknown is set.
+
+Example policy in ~/.config/gopk/config.toml:
+
+  [license]
+  allow = ["MIT", "Apache-2.0", "BSD-*", "ISC"]
+  mode = "block"`,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		direct, _ := cmd.Flags().GetBool("direct")
+
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		policy := cfg.License
+
+		reports, err := service.AuditLicenses(".", policy)
+		if err != nil {
+			return err
+		}
+
+		violations := 0
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "MODULE\tVERSION\tLICENSE\tSTATUS")
+		for _, r := range reports {
+			if direct && !r.Direct {
+				continue
+			}
+			status := r.Status.String()
+			if !r.Cached {
+				status += " (not cached)"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Module, r.Version, r.License, status)
+
+			if r.Status == service.LicenseDenied ||
+				r.Status == service.LicenseUnknown && policy.BlockUnknown {
+				violations++
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+
+		if !policy.Enabled() {
+			fmt.Println("\nNo license policy configured; every license is allowed.")
+			return nil
+		}
+		if violations > 0 {
+			return fmt.Errorf("%d dependencies violate the license policy", violations)
+		}
+		return nil
+	},
+}
+
+func init() {
+	auditLicensesCmd.Flags().BoolP("direct", "d", false, "only check direct dependencies")
+
+	auditCmd.AddCommand(auditLicensesCmd)
+	rootCmd.AddCommand(auditCmd)
+}
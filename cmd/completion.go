@@ -0,0 +1,288 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/lewvy/gopk/config"
+	"github.com/lewvy/gopk/internal/data"
+	"github.com/lewvy/gopk/internal/service"
+	"github.com/lewvy/gopk/internal/store"
+	"github.com/spf13/cobra"
+)
+
+const completeDirective = cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+
+// completionQueries returns the registry to complete from. The hidden
+// __complete command does not parse flags, so the root PersistentPreRun
+// opens the default profile; --profile is only known to the command being
+// completed.
+func completionQueries(cmd *cobra.Command) data.Querier {
+	profile, _ := cmd.Flags().GetString("profile")
+	if profile == "" || profile == config.ActiveProfile() {
+		return queries
+	}
+	config.SetProfile(profile)
+	st, err := store.Open()
+	if err != nil {
+		return nil
+	}
+	return st
+}
+
+// aliasCompletions returns the aliases starting with prefix, ranked by
+// usage and described by their URL, leaving out the ones in skip. Extra
+// aliases follow the primary ones.
+func aliasCompletions(cmd *cobra.Command, prefix string, skip []string) []cobra.Completion {
+	q := completionQueries(cmd)
+	if q == nil {
+		return nil
+	}
+	pkgs, err := service.CompleteAliases(context.Background(), q, prefix)
+	if err != nil {
+		return nil
+	}
+
+	var out []cobra.Completion
+	for _, pkg := range pkgs {
+		if !slices.Contains(skip, pkg.Name) {
+			out = append(out, cobra.CompletionWithDesc(pkg.Name, pkg.Url))
+		}
+	}
+
+	// Extra aliases come after the primary ones.
+	aliases, err := service.Aliases(context.Background(), q)
+	if err != nil {
+		return out
+	}
+	for _, a := range aliases {
+		if strings.HasPrefix(a.Name, prefix) && !slices.Contains(skip, a.Name) {
+			out = append(out, cobra.CompletionWithDesc(a.Name, a.Package.Url))
+		}
+	}
+	return out
+}
+
+func groupCompletions(cmd *cobra.Command, prefix string) []cobra.Completion {
+	q := completionQueries(cmd)
+	if q == nil {
+		return nil
+	}
+	groups, err := service.CompleteGroups(context.Background(), q, prefix)
+	if err != nil {
+		return nil
+	}
+	return groups
+}
+
+// completeAliases completes any number of aliases.
+func completeAliases(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	return aliasCompletions(cmd, toComplete, args), completeDirective
+}
+
+// completeAliasesOrGroups completes any number of aliases, and @group
+// names once an @ is typed, for the commands that resolve names with
+// service.Resolve.
+func completeAliasesOrGroups(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if prefix, ok := strings.CutPrefix(toComplete, "@"); ok {
+		var out []cobra.Completion
+		for _, g := range groupCompletions(cmd, prefix) {
+			if !slices.Contains(args, "@"+g) {
+				out = append(out, cobra.CompletionWithDesc("@"+g, "group"))
+			}
+		}
+		return out, completeDirective
+	}
+	return aliasCompletions(cmd, toComplete, args), completeDirective
+}
+
+// completeAlias completes the alias given as first argument.
+func completeAlias(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	return aliasCompletions(cmd, toComplete, nil), completeDirective
+}
+
+// completeAliasList completes the last element of a comma-separated list of
+// aliases, as taken by StringSlice flags.
+func completeAliasList(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	var done []string
+	prefix := ""
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		prefix = toComplete[:i+1]
+		done = strings.Split(toComplete[:i], ",")
+		toComplete = toComplete[i+1:]
+	}
+
+	var out []cobra.Completion
+	for _, c := range aliasCompletions(cmd, toComplete, done) {
+		out = append(out, prefix+c)
+	}
+	return out, completeDirective
+}
+
+// completeExtraAliases completes any number of extra aliases, as removed
+// by 'gopk alias rm'.
+func completeExtraAliases(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	q := completionQueries(cmd)
+	if q == nil {
+		return nil, completeDirective
+	}
+	aliases, err := service.Aliases(context.Background(), q)
+	if err != nil {
+		return nil, completeDirective
+	}
+
+	var out []cobra.Completion
+	for _, a := range aliases {
+		if strings.HasPrefix(a.Name, toComplete) && !slices.Contains(args, a.Name) {
+			out = append(out, cobra.CompletionWithDesc(a.Name, a.Package.Name))
+		}
+	}
+	return out, completeDirective
+}
+
+func completeGroup(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	return groupCompletions(cmd, toComplete), completeDirective
+}
+
+// completeTemplate completes the project template given as first argument.
+func completeTemplate(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	templates, err := service.ListTemplates()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var out []cobra.Completion
+	for _, t := range templates {
+		if strings.HasPrefix(t.Name, toComplete) {
+			out = append(out, cobra.CompletionWithDesc(t.Name, t.Description))
+		}
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
+
+var completionInstallCmd = &cobra.Command{
+	Use:          "install [bash|zsh|fish...]",
+	Short:        "Install the autocompletion scripts for your shells",
+	SilenceUsage: true,
+	Long: `Write the autocompletion script of each shell where the shell loads it
+from, replacing older versions. Without arguments, the script for the
+shell in $SHELL is installed.
+
+  bash  $XDG_DATA_HOME/bash-completion/completions/gopk
+        (needs the bash-completion package)
+  zsh   $XDG_DATA_HOME/zsh/site-functions/_gopk
+        (add the directory to fpath before running compinit)
+  fish  $XDG_CONFIG_HOME/fish/completions/gopk.fish
+
+$XDG_DATA_HOME defaults to ~/.local/share and $XDG_CONFIG_HOME to
+~/.config. Use --dir to write the scripts to another directory.`,
+
+	ValidArgs: []cobra.Completion{"bash", "zsh", "fish"},
+	Args:      cobra.OnlyValidArgs,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, _ := cmd.Flags().GetString("dir")
+
+		shells := args
+		if len(shells) == 0 {
+			shell := filepath.Base(os.Getenv("SHELL"))
+			if shell != "bash" && shell != "zsh" && shell != "fish" {
+				return fmt.Errorf("cannot tell the shell from $SHELL=%q; name it, as in 'gopk completion install bash'", os.Getenv("SHELL"))
+			}
+			shells = []string{shell}
+		}
+
+		for _, shell := range shells {
+			path, err := completionPath(shell, dir)
+			if err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return err
+			}
+			f, err := os.Create(path)
+			if err != nil {
+				return err
+			}
+			root := cmd.Root()
+			switch shell {
+			case "bash":
+				err = root.GenBashCompletionV2(f, true)
+			case "zsh":
+				err = root.GenZshCompletion(f)
+			case "fish":
+				err = root.GenFishCompletion(f, true)
+			}
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				return err
+			}
+
+			cmd.Printf("Installed %s completions in %s\n", shell, path)
+			if shell == "zsh" {
+				cmd.Printf("  make sure your .zshrc has fpath=(%s $fpath) before compinit\n", filepath.Dir(path))
+			}
+		}
+		return nil
+	},
+}
+
+// completionPath returns where the completion script of shell goes, in dir
+// when it is set.
+func completionPath(shell, dir string) (string, error) {
+	name := map[string]string{"bash": "gopk", "zsh": "_gopk", "fish": "gopk.fish"}[shell]
+	if dir != "" {
+		return filepath.Join(dir, name), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		configHome = filepath.Join(home, ".config")
+	}
+
+	switch shell {
+	case "bash":
+		if d := os.Getenv("BASH_COMPLETION_USER_DIR"); d != "" {
+			return filepath.Join(d, "completions", name), nil
+		}
+		return filepath.Join(dataHome, "bash-completion", "completions", name), nil
+	case "zsh":
+		return filepath.Join(dataHome, "zsh", "site-functions", name), nil
+	default:
+		return filepath.Join(configHome, "fish", "completions", name), nil
+	}
+}
+
+func init() {
+	completionInstallCmd.Flags().String("dir", "", "directory to write the scripts to")
+
+	// Create cobra's completion command now, rather than when executing,
+	// so that install can be added to it.
+	rootCmd.InitDefaultCompletionCmd()
+	for _, c := range rootCmd.Commands() {
+		if c.Name() == "completion" {
+			c.AddCommand(completionInstallCmd)
+		}
+	}
+
+}
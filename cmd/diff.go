@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/lewvy/gopk/internal/service"
+	"github.com/spf13/cobra"
+)
+
+var diffCmd = &cobra.Command{
+	Use:          "diff",
+	Short:        "Compare the current module with the registry",
+	SilenceUsage: true,
+	Long: `Compare the go.mod of the current module with your gopk registry.
+
+The report lists:
+  - direct requirements that are not saved in the registry, with the
+    alias they would be saved under
+  - saved packages pinned to another version than the one required
+  - for each group, whether the module requires all, some or none of
+    its members
+
+Use --output json (or --json), jsonl or a template run with the report
+for a machine-readable report; --output tsv prints one line per unsaved
+module, drift and group. Use --add to save the unsaved requirements at
+the versions the module uses.`,
+
+	Args: cobra.NoArgs,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJSON, _ := cmd.Flags().GetBool("json")
+		add, _ := cmd.Flags().GetBool("add")
+
+		ctx := context.Background()
+		diff, err := service.DiffProject(ctx, queries, ".")
+		if err != nil {
+			return err
+		}
+
+		if asJSON {
+			cmd.Flags().Set("output", outputJSON)
+		}
+		written, err := writeValue(cmd, diff)
+		if err != nil {
+			return err
+		}
+		if !written {
+			if tableOutput(cmd) {
+				err = printDiff(cmd, diff, add)
+			} else {
+				err = printDiffTSV(cmd, diff)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		if !add || len(diff.Unsaved) == 0 {
+			return nil
+		}
+		added, err := service.AddUnsaved(ctx, queries, diff)
+		for _, pkg := range added {
+			fmt.Fprintf(cmd.ErrOrStderr(), "saved %s as %s\n", pkg.Url, pkg.Name)
+		}
+		return err
+	},
+}
+
+func printDiff(cmd *cobra.Command, diff service.ProjectDiff, add bool) error {
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "Module %s\n", diff.Module)
+
+	if len(diff.Unsaved) > 0 {
+		fmt.Fprintf(w, "\nNot in the registry (%d):\n", len(diff.Unsaved))
+		for _, m := range diff.Unsaved {
+			alias := "alias taken"
+			if m.Alias != "" {
+				alias = "as " + m.Alias
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", m.Path, m.Version, alias)
+		}
+	}
+
+	if len(diff.Drifted) > 0 {
+		fmt.Fprintf(w, "\nVersion drift (%d):\n", len(diff.Drifted))
+		for _, d := range diff.Drifted {
+			fmt.Fprintf(w, "  %s\t%s\tsaved %s\tproject %s\n", d.Name, d.Module, d.Saved, d.Project)
+		}
+	}
+
+	if len(diff.Groups) > 0 {
+		fmt.Fprintln(w, "\nGroups:")
+		for _, g := range diff.Groups {
+			missing := ""
+			if g.Coverage == service.CoveragePartial {
+				missing = "missing: " + strings.Join(g.Missing, ", ")
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%d/%d\t%s\n", g.Name, g.Coverage, len(g.Present), len(g.Present)+len(g.Missing), missing)
+		}
+	}
+
+	if len(diff.Unsaved) == 0 && len(diff.Drifted) == 0 {
+		fmt.Fprintln(w, "\nEvery direct requirement is saved at the required version.")
+	} else if len(diff.Unsaved) > 0 && !add {
+		fmt.Fprintf(w, "\nRun 'gopk diff --add' to save the %d unsaved module(s).\n", len(diff.Unsaved))
+	}
+	return w.Flush()
+}
+
+// printDiffTSV prints the diff as tab-separated lines whose first field
+// is unsaved, drifted or group.
+func printDiffTSV(cmd *cobra.Command, diff service.ProjectDiff) error {
+	out := cmd.OutOrStdout()
+	for _, m := range diff.Unsaved {
+		fmt.Fprintf(out, "unsaved\t%s\t%s\t%s\n", m.Path, m.Version, m.Alias)
+	}
+	for _, d := range diff.Drifted {
+		fmt.Fprintf(out, "drifted\t%s\t%s\t%s\t%s\n", d.Name, d.Module, d.Saved, d.Project)
+	}
+	for _, g := range diff.Groups {
+		fmt.Fprintf(out, "group\t%s\t%s\t%s\t%s\n", g.Name, g.Coverage, strings.Join(g.Present, ","), strings.Join(g.Missing, ","))
+	}
+	return nil
+}
+
+func init() {
+	diffCmd.Flags().Bool("json", false, "print the report as JSON, like --output json")
+	diffCmd.Flags().Bool("add", false, "save the unsaved requirements in the registry")
+
+	rootCmd.AddCommand(diffCmd)
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/lewvy/gopk/internal/modcache"
+	"github.com/lewvy/gopk/internal/service"
+	"github.com/spf13/cobra"
+)
+
+var enrichCmd = &cobra.Command{
+	Use:          "enrich [alias...]",
+	Short:        "Read package metadata from the local module cache",
+	SilenceUsage: true,
+	Long: `Enrich saved packages with metadata from the local Go module cache.
+
+For each package, enrich locates the saved version (or the newest cached
+version when the saved one is "latest") in $GOMODCACHE and records the
+package synopsis, the go directive and dependency count of its go.mod,
+and its license file. Nothing is downloaded: packages that are not in
+the module cache are reported and skipped.
+
+Without arguments, every saved package is enriched.`,
+
+	ValidArgsFunction: completeAliases,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		results, err := service.Enrich(context.Background(), queries, args)
+		if err != nil {
+			return err
+		}
+
+		failed := 0
+		for _, r := range results {
+			if r.Err != nil {
+				if !errors.Is(r.Err, modcache.ErrNotCached) {
+					failed++
+				}
+				fmt.Printf("%s: %v\n", r.Package.Name, r.Err)
+				continue
+			}
+			fmt.Printf("%s %s %s\n", r.Package.Name, r.Info.Version, r.Info.Synopsis)
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("failed to enrich %d package(s)", failed)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(enrichCmd)
+}
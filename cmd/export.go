@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+
+	"github.com/lewvy/gopk/internal/service"
+	"github.com/lewvy/gopk/pkg/gopk"
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:          "export",
+	Short:        "Export the registry as JSON, YAML or TOML",
+	SilenceUsage: true,
+	Long: `Export packages, versions, snippets, groups and group memberships.
+
+The export is written to stdout, or to the file given with --file, and
+can be loaded again with 'gopk apply <file>'. Use --group to export a
+single group and its packages.
+
+Examples:
+  gopk export > backup.toml
+  gopk export --format json --group web -f web.json`,
+
+	Args: cobra.NoArgs,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		group, _ := cmd.Flags().GetString("group")
+		file, _ := cmd.Flags().GetString("file")
+
+		if format == "" {
+			format = service.FormatFromPath(file)
+		}
+
+		opts := gopk.ExportOptions{Format: format, Group: group}
+		if file == "" {
+			return registry.Export(context.Background(), cmd.OutOrStdout(), opts)
+		}
+
+		var buf bytes.Buffer
+		if err := registry.Export(context.Background(), &buf, opts); err != nil {
+			return err
+		}
+		return os.WriteFile(file, buf.Bytes(), 0o644)
+	},
+}
+
+func init() {
+	exportCmd.Flags().StringP("format", "F", "", "output format: json, yaml or toml (default from --file, else toml)")
+	exportCmd.Flags().StringP("group", "g", "", "only export this group")
+	exportCmd.Flags().StringP("file", "f", "", "write to file instead of stdout")
+	exportCmd.RegisterFlagCompletionFunc("group", completeGroup)
+
+	rootCmd.AddCommand(exportCmd)
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var forkCmd = &cobra.Command{
+	Use:          "fork <alias> [dir|module@version]",
+	Short:        "Attach a local checkout or fork to a saved package",
+	SilenceUsage: true,
+	Long: `Save a replacement for the module of a saved package.
+
+The replacement is either a local directory, such as ~/src/zap, or a fork
+module with a version, such as github.com/me/zap@v1.27.0. Local paths are
+stored as absolute paths. 'gopk get --local' then runs
+'go mod edit -replace' for the module before fetching it; with --always,
+every 'gopk get' does.
+
+A warning is printed when the directory has no go.mod or declares
+another module path than the package's module.
+
+Without a replacement, the saved one is printed. Use --clear to remove it,
+and 'gopk unreplace' to drop the replace directive from a go.mod.`,
+
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: completeAlias,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		remove, _ := cmd.Flags().GetBool("clear")
+		always, _ := cmd.Flags().GetBool("always")
+
+		ctx := context.Background()
+		out := cmd.OutOrStdout()
+		if len(args) == 1 && !remove {
+			pkgs, err := registry.Resolve(ctx, args[0])
+			if err != nil {
+				return err
+			}
+			pkg := pkgs[0]
+			switch {
+			case pkg.Replace == "":
+				fmt.Fprintf(out, "%s has no replacement.\n", pkg.Name)
+			case pkg.ReplaceAlways:
+				fmt.Fprintf(out, "%s => %s (always)\n", pkg.Module, pkg.Replace)
+			default:
+				fmt.Fprintf(out, "%s => %s (with --local)\n", pkg.Module, pkg.Replace)
+			}
+			return nil
+		}
+		if remove && len(args) == 2 {
+			return fmt.Errorf("--clear takes no replacement")
+		}
+
+		target := ""
+		if len(args) == 2 {
+			target = args[1]
+		}
+		pkg, err := registry.SetReplace(ctx, args[0], target, always)
+		if err != nil {
+			return err
+		}
+		if pkg.Replace == "" {
+			fmt.Fprintf(out, "Removed the replacement of %s.\n", pkg.Name)
+		} else {
+			fmt.Fprintf(out, "%s => %s\n", pkg.Module, pkg.Replace)
+		}
+		return nil
+	},
+}
+
+var unreplaceCmd = &cobra.Command{
+	Use:          "unreplace <alias> [alias...]",
+	Short:        "Restore the upstream modules of saved packages in go.mod",
+	SilenceUsage: true,
+	Long: `Drop the replace directives for the modules of the given aliases from
+the go.mod of the current module, then run 'go get' so that go.mod and
+go.sum point at the upstream module again, at the saved version.
+
+The replacement saved with 'gopk fork' is kept; use 'gopk fork --clear'
+to remove it.`,
+
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeAliasesOrGroups,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return registry.Unreplace(context.Background(), args)
+	},
+}
+
+func init() {
+	forkCmd.Flags().Bool("always", false, "apply the replacement on every 'gopk get', not only with --local")
+	forkCmd.Flags().Bool("clear", false, "remove the saved replacement")
+
+	rootCmd.AddCommand(forkCmd)
+	rootCmd.AddCommand(unreplaceCmd)
+}
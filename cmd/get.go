@@ -1,7 +1,9 @@
 package cmd
 
 import (
-	"github.com/lewvy/gopk/cmd/internal/service"
+	"context"
+
+	"github.com/lewvy/gopk/pkg/gopk"
 	"github.com/spf13/cobra"
 )
 
@@ -12,18 +14,38 @@ var getCmd = &cobra.Command{
 	Long: `Install Go modules by alias from your gopk registry.
 
 The get command resolves aliases stored in gopk and runs 'go get'
-for each selected package in the current Go module.
+for each selected package in the current Go module. Packages saved as
+tools are added with 'go get -tool', so they are recorded in the tool
+directive of go.mod and run with 'go tool'. An argument of the form
+@group stands for every member of the group.
 
 This command is project-specific and requires an existing go.mod file.
-It does not modify your gopk registry.`,
+It does not modify your gopk registry.
+
+When a vulnerability database is configured, saved versions with known
+vulnerabilities are reported before installing. Use --fail-on-vuln to
+refuse to install them.
 
-	Args: cobra.MinimumNArgs(1),
+Packages with a fork or local checkout saved with 'gopk fork' are
+replaced in go.mod with 'go mod edit -replace' before fetching when
+--local is given, or always if the fork was saved with --always. Use
+'gopk unreplace' to go back to the upstream module.`,
+
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeAliasesOrGroups,
 
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return service.GetFromName(args, queries)
+		failOnVuln, _ := cmd.Flags().GetBool("fail-on-vuln")
+		fuzzy, _ := cmd.Flags().GetBool("fuzzy")
+		local, _ := cmd.Flags().GetBool("local")
+		return registry.Install(context.Background(), args, gopk.InstallOptions{FailOnVuln: failOnVuln, Fuzzy: fuzzy, Local: local})
 	},
 }
 
 func init() {
+	getCmd.Flags().Bool("fail-on-vuln", false, "do not install versions with known vulnerabilities")
+	getCmd.Flags().Bool("fuzzy", false, "resolve an unknown alias to the only package it fuzzy matches")
+	getCmd.Flags().BoolP("local", "L", false, "replace modules with their saved forks or local checkouts")
+
 	rootCmd.AddCommand(getCmd)
 }
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/lewvy/gopk/pkg/gopk"
+	"github.com/spf13/cobra"
+)
+
+var groupCmd = &cobra.Command{
+	Use:          "group",
+	Short:        "Show the groups of saved packages",
+	SilenceUsage: true,
+	Long: `Show the groups of saved packages and their members.
+
+Groups are created and filled in the TUI, installed with 'gopk get @group'
+and removed with 'gopk rm -g'.
+
+Examples:
+  gopk group list
+  gopk group list -o 'template={{.Name}}\t{{len .Packages}}'`,
+
+	Args: cobra.NoArgs,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return groupListCmd.RunE(cmd, args)
+	},
+}
+
+var groupListCmd = &cobra.Command{
+	Use:          "list",
+	Short:        "List the groups and their packages",
+	SilenceUsage: true,
+	Args:         cobra.NoArgs,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		groups, err := registry.Groups(context.Background())
+		if err != nil {
+			return err
+		}
+		if len(groups) == 0 && tableOutput(cmd) {
+			cmd.Println("No groups saved; create one in the TUI.")
+			return nil
+		}
+
+		return writeListing(cmd, groups, []column[gopk.Group]{
+			{"NAME", func(g gopk.Group) string { return g.Name }},
+			{"SIZE", func(g gopk.Group) string { return strconv.Itoa(len(g.Packages)) }},
+			{"PACKAGES", func(g gopk.Group) string { return strings.Join(g.Packages, ", ") }},
+		})
+	},
+}
+
+func init() {
+	groupCmd.AddCommand(groupListCmd)
+
+	rootCmd.AddCommand(groupCmd)
+}
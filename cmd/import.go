@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/lewvy/gopk/internal/service"
+	"github.com/spf13/cobra"
+)
+
+var importCmd = &cobra.Command{
+	Use:          "import <alias> [alias...] [file.go] | import <file>",
+	Short:        "Add imports for saved packages to a Go file, or load an export",
+	SilenceUsage: true,
+	Long: `Insert imports for packages in your gopk registry into a Go source file.
+
+If the last argument ends in .go, that file is rewritten in place.
+Otherwise the source is read from stdin and written to stdout, which
+makes the command usable as an editor filter.
+
+Packages saved with an import name (gopk add --import-name) are imported
+under that name. Imports are merged into the existing groups and sorted
+like goimports does. Use --get to also run 'go get' for modules that the
+enclosing go.mod does not require yet.
+
+A single .toml, .json, .yaml or .yml argument is a file written by
+'gopk export' instead: its packages, snippets, groups and templates are
+merged into the registry, as 'gopk apply <file>' does. Use apply for
+--prune and --dry-run.
+
+Examples:
+  gopk import zap main.go
+  gopk import zerolog errgroup < handler.go
+  gopk import backup.toml`,
+
+	Args: cobra.MinimumNArgs(1),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		get, _ := cmd.Flags().GetBool("get")
+
+		if len(args) == 1 && service.IsRegistryFile(args[0]) {
+			return applyFile(cmd, args[0], false, false, false)
+		}
+
+		var filename string
+		if last := args[len(args)-1]; strings.HasSuffix(last, ".go") {
+			filename = last
+			args = args[:len(args)-1]
+		}
+		if len(args) == 0 {
+			return cmd.Usage()
+		}
+
+		var src []byte
+		var err error
+		if filename != "" {
+			src, err = os.ReadFile(filename)
+		} else {
+			src, err = io.ReadAll(cmd.InOrStdin())
+		}
+		if err != nil {
+			return err
+		}
+
+		out, err := service.Import(context.Background(), queries, args, filename, src, get)
+		if err != nil {
+			return err
+		}
+
+		if filename == "" {
+			_, err = cmd.OutOrStdout().Write(out)
+			return err
+		}
+		info, err := os.Stat(filename)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filename, out, info.Mode())
+	},
+}
+
+func init() {
+	importCmd.Flags().BoolP("get", "g", false, "run 'go get' for modules missing from go.mod")
+
+	rootCmd.AddCommand(importCmd)
+}
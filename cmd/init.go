@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"github.com/lewvy/gopk/internal/service"
+	"github.com/spf13/cobra"
+)
+
+var initCmd = &cobra.Command{
+	Use:          "init <module-path>",
+	Short:        "Create a new module and install a group into it",
+	SilenceUsage: true,
+	Long: `Bootstrap a new Go module from your gopk registry.
+
+The init command creates a directory named after the last element of the
+module path, without a major version suffix such as /v2 (or --dir), runs
+'go mod init' in it and installs every package of --group at its saved
+version.
+
+With --main, a main.go is assembled from the snippets of the group's
+packages: declarations go before func main, statements inside it, in
+group order. --snippet picks the snippet to use from each package;
+by default the first one by name is used. Alternatively, --template
+renders main.go from a text/template file, which receives the module
+path as .Module, the directory's default name as .Name, and the group's packages as
+.Packages, each with .Name, .Path, .ImportName, .Version and .Snippets.
+Standard library imports are added automatically.
+
+Example:
+  gopk init github.com/me/svc --group web --main --snippet setup`,
+
+	Args: cobra.ExactArgs(1),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		modulePath := args[0]
+		dir, _ := cmd.Flags().GetString("dir")
+		group, _ := cmd.Flags().GetString("group")
+		withMain, _ := cmd.Flags().GetBool("main")
+		snippet, _ := cmd.Flags().GetString("snippet")
+		templatePath, _ := cmd.Flags().GetString("template")
+		failOnVuln, _ := cmd.Flags().GetBool("fail-on-vuln")
+		local, _ := cmd.Flags().GetBool("local")
+
+		if dir == "" {
+			dir = service.ProjectName(modulePath)
+		}
+
+		opts := service.InitOptions{
+			Group:   group,
+			Main:    withMain || snippet != "",
+			Snippet: snippet,
+			InstallOptions: service.InstallOptions{
+				FailOnVuln: failOnVuln,
+				Local:      local,
+			},
+		}
+		if templatePath != "" {
+			content, err := os.ReadFile(templatePath)
+			if err != nil {
+				return err
+			}
+			opts.Template = string(content)
+		}
+
+		if err := service.InitModule(context.Background(), queries, dir, modulePath, opts); err != nil {
+			return err
+		}
+		cmd.Printf("Created %s in %s\n", modulePath, dir)
+		return nil
+	},
+}
+
+func init() {
+	initCmd.Flags().StringP("dir", "d", "", "directory to create (default: last element of the module path)")
+	initCmd.Flags().StringP("group", "g", "", "group to install into the new module")
+	initCmd.Flags().Bool("main", false, "write a main.go assembled from the group's snippets")
+	initCmd.Flags().String("snippet", "", "name of the snippet to use from each package (implies --main)")
+	initCmd.Flags().StringP("template", "t", "", "text/template file to render main.go from")
+	initCmd.Flags().Bool("fail-on-vuln", false, "do not install versions with known vulnerabilities")
+	initCmd.Flags().BoolP("local", "L", false, "replace modules with their saved forks or local checkouts")
+	initCmd.RegisterFlagCompletionFunc("group", completeGroup)
+
+	rootCmd.AddCommand(initCmd)
+}
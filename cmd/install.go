@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/lewvy/gopk/pkg/gopk"
+	"github.com/spf13/cobra"
+)
+
+var installCmd = &cobra.Command{
+	Use:          "install <alias> [alias...]",
+	Short:        "Install saved tools globally with go install",
+	SilenceUsage: true,
+	Long: `Install tools by alias from your gopk registry.
+
+The install command runs 'go install <path>@<version>' for each alias,
+which builds the tool and places the binary in GOBIN. Unlike 'gopk get',
+it does not need a go.mod file and does not change the current module.
+
+Only packages saved with --kind tool or --kind both can be installed.
+License and vulnerability checks apply as with 'gopk get'.`,
+
+	Args:              cobra.MinimumNArgs(1),
+	ValidArgsFunction: completeAliasesOrGroups,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		failOnVuln, _ := cmd.Flags().GetBool("fail-on-vuln")
+		fuzzy, _ := cmd.Flags().GetBool("fuzzy")
+		return registry.InstallTools(context.Background(), args, gopk.InstallOptions{FailOnVuln: failOnVuln, Fuzzy: fuzzy})
+	},
+}
+
+func init() {
+	installCmd.Flags().Bool("fail-on-vuln", false, "do not install versions with known vulnerabilities")
+	installCmd.Flags().Bool("fuzzy", false, "resolve an unknown alias to the only package it fuzzy matches")
+
+	rootCmd.AddCommand(installCmd)
+}
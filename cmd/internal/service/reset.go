@@ -1,10 +0,0 @@
-package service
-
-import (
-	"github.com/lewvy/gopk/config"
-)
-
-func Reset() error {
-	return config.ResetDB()
-
-}
@@ -1,9 +1,12 @@
 package cmd
 
 import (
-	"fmt"
+	"context"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/lewvy/gopk/cmd/internal/service"
+	"github.com/lewvy/gopk/pkg/gopk"
 	"github.com/spf13/cobra"
 )
 
@@ -13,20 +16,86 @@ var listCmd = &cobra.Command{
 	Long: `List all packages saved in your gopk registry.
     
 By default, packages are sorted by when they were last used.
-Use the --freq flag to sort by most frequently used instead.`,
+Use the --freq flag to sort by most frequently used instead.
+
+Packages enriched with 'gopk enrich' show their one-line description.
+When a vulnerability database is configured, saved versions with known
+vulnerabilities are flagged. Tools are marked with their kind. Packages
+from read-only layers are listed after your own, with the name of the
+layer they come from.
+
+Use --output json, jsonl or tsv for scripts, or a text/template run for
+each package, which has the fields of gopk.Package and Vulns:
+  gopk list -o 'template={{.Name}}\t{{.URL}}@{{.Version}}'`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		limit, _ := cmd.Flags().GetInt("limit")
 		byFreq, _ := cmd.Flags().GetBool("freq")
 
-		pkgs, err := service.List(queries, limit, byFreq)
-		for _, p := range pkgs {
-			fmt.Println(p.Name, p.Url, p.Freq.Int64)
+		ctx := context.Background()
+		var pkgs []gopk.Package
+		// ListOptions.Limit 0 means no limit, but --limit 0 lists nothing.
+		if limit != 0 {
+			var err error
+			pkgs, err = registry.List(ctx, gopk.ListOptions{Limit: limit, ByFrequency: byFreq})
+			if err != nil {
+				return err
+			}
+		}
+
+		vulns, err := registry.Vulns(ctx, pkgs)
+		if err != nil {
+			return err
+		}
+
+		rows := make([]listRow, len(pkgs))
+		layered := false
+		for i, p := range pkgs {
+			rows[i] = listRow{Package: p, Vulns: vulns[p.ID]}
+			layered = layered || p.Layer != gopk.PersonalLayer
 		}
 
-		return err
+		columns := []column[listRow]{
+			{"NAME", func(r listRow) string { return r.Name }},
+			{"URL", func(r listRow) string { return r.URL }},
+			{"VERSION", func(r listRow) string { return r.Version }},
+			{"KIND", func(r listRow) string {
+				if r.Kind == "library" {
+					return ""
+				}
+				return r.Kind
+			}},
+			{"USES", func(r listRow) string { return strconv.FormatInt(r.Freq, 10) }},
+			{"LAST USED", func(r listRow) string {
+				if r.LastUsed.IsZero() {
+					return "never"
+				}
+				return r.LastUsed.Local().Format(time.DateOnly)
+			}},
+		}
+		if layered {
+			columns = append(columns, column[listRow]{"LAYER", func(r listRow) string { return r.Layer }})
+		}
+		columns = append(columns,
+			column[listRow]{"NOTES", func(r listRow) string { return vulnNote(r.Vulns) }},
+			column[listRow]{"SYNOPSIS", func(r listRow) string { return r.Synopsis }},
+		)
+		return writeListing(cmd, rows, columns)
 	},
 }
 
+// listRow is a package as printed by list.
+type listRow struct {
+	gopk.Package
+	Vulns []string `json:"vulns,omitempty"`
+}
+
+func vulnNote(ids []string) string {
+	if len(ids) == 0 {
+		return ""
+	}
+	return "vulnerable: " + strings.Join(ids, ", ")
+}
+
 func init() {
 	listCmd.Flags().IntP("limit", "l", -1, "limit the number of results")
 	listCmd.Flags().BoolP("freq", "f", false, "sort results by frequency of use")
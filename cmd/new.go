@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/lewvy/gopk/internal/service"
+	"github.com/spf13/cobra"
+)
+
+var newCmd = &cobra.Command{
+	Use:          "new <template> <module-path>",
+	Short:        "Create a new module from a project template",
+	SilenceUsage: true,
+	Long: `Create a new Go module from a saved project template.
+
+The new command creates a directory named after the last element of the
+module path, without a major version suffix such as /v2 (or --dir), runs
+'go mod init' in it, writes the files of the template, installs the
+packages it lists at their saved versions and then runs its commands in
+the new directory. With --group, the members of a group are installed as
+well.
+
+File paths, file bodies and commands are text/template sources. They
+receive the same data as 'gopk init --template': .Module, .Name and
+.Packages, plus {{(.Package "zap").Path}} and {{.Snippet "zap" "setup"}}
+to reach a package by alias. A trailing .tmpl is dropped from file names,
+and Go files get their standard library imports fixed.
+
+Manage templates with 'gopk template'.
+
+Examples:
+  gopk new cli github.com/me/tool
+  gopk new service github.com/me/api --group web`,
+
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeTemplate,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, modulePath := args[0], args[1]
+		dir, _ := cmd.Flags().GetString("dir")
+		noCommands, _ := cmd.Flags().GetBool("no-commands")
+		failOnVuln, _ := cmd.Flags().GetBool("fail-on-vuln")
+		local, _ := cmd.Flags().GetBool("local")
+		group, _ := cmd.Flags().GetString("group")
+
+		if dir == "" {
+			dir = service.ProjectName(modulePath)
+		}
+
+		opts := service.NewProjectOptions{
+			Group:        group,
+			SkipCommands: noCommands,
+			Output:       cmd.OutOrStdout(),
+			InstallOptions: service.InstallOptions{
+				FailOnVuln: failOnVuln,
+				Local:      local,
+			},
+		}
+		if err := service.NewProject(context.Background(), queries, name, dir, modulePath, opts); err != nil {
+			return err
+		}
+		cmd.Printf("Created %s in %s from %s\n", modulePath, dir, name)
+		return nil
+	},
+}
+
+func init() {
+	newCmd.Flags().StringP("dir", "d", "", "directory to create (default: last element of the module path)")
+	newCmd.Flags().StringP("group", "g", "", "also install the members of this group")
+	newCmd.Flags().Bool("no-commands", false, "do not run the commands of the template")
+	newCmd.Flags().Bool("fail-on-vuln", false, "do not install versions with known vulnerabilities")
+	newCmd.Flags().BoolP("local", "L", false, "replace modules with their saved forks or local checkouts")
+
+	newCmd.RegisterFlagCompletionFunc("group", completeGroup)
+
+	rootCmd.AddCommand(newCmd)
+}
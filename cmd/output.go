@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/spf13/cobra"
+)
+
+// Output formats selected with the global --output flag.
+const (
+	outputTable    = "table"
+	outputJSON     = "json"
+	outputJSONL    = "jsonl"
+	outputTSV      = "tsv"
+	outputTemplate = "template"
+)
+
+// output is the format selected with --output. tmpl is set for templates.
+type output struct {
+	format string
+	tmpl   *template.Template
+}
+
+// outputOf parses the --output flag of cmd. Templates are given as
+// template=<text>, as in -o 'template={{.Name}} {{.URL}}'.
+func outputOf(cmd *cobra.Command) (output, error) {
+	value, _ := cmd.Flags().GetString("output")
+	format, text, hasText := strings.Cut(value, "=")
+
+	switch format {
+	case outputTable, outputJSON, outputJSONL, outputTSV:
+		if hasText {
+			return output{}, fmt.Errorf("--output %s takes no argument", format)
+		}
+		return output{format: format}, nil
+	case outputTemplate:
+		if text == "" {
+			return output{}, fmt.Errorf("--output template needs a template, as in -o 'template={{.Name}}'")
+		}
+		// Templates are written on the command line, where \n and \t
+		// are easier to type than the characters themselves.
+		text = strings.NewReplacer(`\n`, "\n", `\t`, "\t").Replace(text)
+		tmpl, err := template.New("output").Parse(text)
+		if err != nil {
+			return output{}, err
+		}
+		return output{format: format, tmpl: tmpl}, nil
+	default:
+		return output{}, fmt.Errorf("unknown output format %q: use table, json, jsonl, tsv or template=<text>", value)
+	}
+}
+
+// tableOutput reports whether cmd prints a human table, so that it can
+// print messages that would get in the way of scripts.
+func tableOutput(cmd *cobra.Command) bool {
+	o, err := outputOf(cmd)
+	return err == nil && o.format == outputTable
+}
+
+// column is a field of the records of a listing, printed by table and tsv
+// output.
+type column[T any] struct {
+	header string
+	value  func(T) string
+}
+
+// writeListing writes records in the format selected with --output.
+// Tables and TSV print the columns, with a header; JSON, JSON lines and
+// templates get the records themselves, templates once per record.
+func writeListing[T any](cmd *cobra.Command, records []T, columns []column[T]) error {
+	o, err := outputOf(cmd)
+	if err != nil {
+		return err
+	}
+	w := cmd.OutOrStdout()
+
+	switch o.format {
+	case outputJSON:
+		if records == nil {
+			records = []T{}
+		}
+		return writeJSON(w, records)
+	case outputJSONL:
+		enc := json.NewEncoder(w)
+		for _, r := range records {
+			if err := enc.Encode(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	case outputTemplate:
+		for _, r := range records {
+			if err := executeLine(w, o.tmpl, r); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	clean := tsvField
+	flush := func() error { return nil }
+	if o.format == outputTable {
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		w, flush = tw, tw.Flush
+		clean = func(s string) string { return s }
+	}
+
+	fields := make([]string, len(columns))
+	for i, c := range columns {
+		fields[i] = c.header
+	}
+	fmt.Fprintln(w, strings.Join(fields, "\t"))
+	for _, r := range records {
+		for i, c := range columns {
+			fields[i] = clean(c.value(r))
+		}
+		fmt.Fprintln(w, strings.Join(fields, "\t"))
+	}
+	return flush()
+}
+
+// writeValue writes a single value for the machine-readable formats and
+// reports whether it did; the caller prints tables and TSV itself.
+func writeValue(cmd *cobra.Command, v any) (bool, error) {
+	o, err := outputOf(cmd)
+	if err != nil {
+		return false, err
+	}
+	w := cmd.OutOrStdout()
+
+	switch o.format {
+	case outputJSON:
+		return true, writeJSON(w, v)
+	case outputJSONL:
+		return true, json.NewEncoder(w).Encode(v)
+	case outputTemplate:
+		return true, executeLine(w, o.tmpl, v)
+	default:
+		return false, nil
+	}
+}
+
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// executeLine executes tmpl with v and ends the output with a newline.
+func executeLine(w io.Writer, tmpl *template.Template, v any) error {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, v); err != nil {
+		return err
+	}
+	line := b.String()
+	if !strings.HasSuffix(line, "\n") {
+		line += "\n"
+	}
+	_, err := io.WriteString(w, line)
+	return err
+}
+
+// tsvField replaces the tabs and newlines of s, which would break the
+// columns, with spaces.
+func tsvField(s string) string {
+	return strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ").Replace(s)
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/lewvy/gopk/cmd/tui"
+	"github.com/lewvy/gopk/internal/service"
+	"github.com/spf13/cobra"
+)
+
+var pickCmd = &cobra.Command{
+	Use:          "pick [query]",
+	Short:        "Pick packages with an inline fuzzy finder and print them",
+	SilenceUsage: true,
+	Long: `Open a compact fuzzy finder over your saved packages and print the
+chosen ones to stdout, one per line.
+
+The finder is drawn on the terminal below the prompt, not on a separate
+screen, and uses the same matching as the TUI search. Type to filter,
+move with the arrow keys, ctrl+n and ctrl+p, select several packages with
+tab and accept with enter; without a selection, the package under the
+cursor is picked. Esc cancels and exits with an error.
+
+By default the aliases are printed. Use --url for import paths, or
+--import for import specs, as written in an import block.
+
+Examples:
+  go get $(gopk pick --url)
+  gopk get $(gopk pick zap)
+  :r !gopk pick --import        (in Vim)`,
+
+	Args: cobra.MaximumNArgs(1),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asURL, _ := cmd.Flags().GetBool("url")
+		asImport, _ := cmd.Flags().GetBool("import")
+		height, _ := cmd.Flags().GetInt("height")
+		if asURL && asImport {
+			return errors.New("--url and --import cannot be used together")
+		}
+
+		pkgs, err := service.CompleteAliases(context.Background(), queries, "")
+		if err != nil {
+			return err
+		}
+
+		opts := tui.PickOptions{Height: height}
+		if len(args) == 1 {
+			opts.Query = args[0]
+		}
+		picked, err := tui.Pick(pkgs, opts)
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		for _, pkg := range picked {
+			switch {
+			case asURL:
+				fmt.Fprintln(out, pkg.Url)
+			case asImport && pkg.ImportName != "":
+				fmt.Fprintf(out, "%s %s\n", pkg.ImportName, strconv.Quote(pkg.Url))
+			case asImport:
+				fmt.Fprintln(out, strconv.Quote(pkg.Url))
+			default:
+				fmt.Fprintln(out, pkg.Name)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	pickCmd.Flags().Bool("url", false, "print import paths instead of aliases")
+	pickCmd.Flags().Bool("import", false, "print import specs instead of aliases")
+	pickCmd.Flags().Int("height", 10, "number of packages shown at once")
+
+	rootCmd.AddCommand(pickCmd)
+}
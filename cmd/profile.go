@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/lewvy/gopk/config"
+	"github.com/spf13/cobra"
+)
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage registry profiles",
+	Long: `Profiles are independent registries, each with its own database.
+
+The active profile is chosen with --profile, then $GOPK_PROFILE, and
+falls back to "default", which keeps the database gopk has always used.`,
+
+	// Profile commands manage databases themselves and must work when the
+	// selected profile does not exist yet.
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if profile, _ := cmd.Flags().GetString("profile"); profile != "" {
+			config.SetProfile(profile)
+		}
+	},
+}
+
+var profileListCmd = &cobra.Command{
+	Use:          "list",
+	Short:        "List profiles",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		names, err := config.ListProfiles()
+		if err != nil {
+			return err
+		}
+
+		active := config.ActiveProfile()
+		rows := make([]profileRow, len(names))
+		for i, name := range names {
+			rows[i] = profileRow{Name: name, Active: name == active}
+		}
+		return writeListing(cmd, rows, []column[profileRow]{
+			{"NAME", func(r profileRow) string { return r.Name }},
+			{"ACTIVE", func(r profileRow) string {
+				if r.Active {
+					return "*"
+				}
+				return ""
+			}},
+		})
+	},
+}
+
+// profileRow is a profile as printed by profile list.
+type profileRow struct {
+	Name   string `json:"name"`
+	Active bool   `json:"active"`
+}
+
+var profileCreateCmd = &cobra.Command{
+	Use:          "create <name>",
+	Short:        "Create an empty profile",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.CreateProfile(args[0]); err != nil {
+			return err
+		}
+		fmt.Printf("created profile %s\n", args[0])
+		return nil
+	},
+}
+
+var profileCopyCmd = &cobra.Command{
+	Use:          "copy <src> <dst>",
+	Short:        "Create a profile from a copy of another",
+	Args:         cobra.ExactArgs(2),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.CopyProfile(args[0], args[1]); err != nil {
+			return err
+		}
+		fmt.Printf("copied profile %s to %s\n", args[0], args[1])
+		return nil
+	},
+}
+
+var profileDeleteCmd = &cobra.Command{
+	Use:          "delete <name>",
+	Aliases:      []string{"rm"},
+	Short:        "Delete a profile and its database",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if args[0] == config.ActiveProfile() {
+			return fmt.Errorf("profile %s is active; switch profiles before deleting it", args[0])
+		}
+		if err := config.DeleteProfile(args[0]); err != nil {
+			return err
+		}
+		fmt.Printf("deleted profile %s\n", args[0])
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(profileCmd)
+	profileCmd.AddCommand(profileListCmd, profileCreateCmd, profileCopyCmd, profileDeleteCmd)
+}
@@ -6,7 +6,7 @@ package cmd
 import (
 	"fmt"
 
-	"github.com/lewvy/gopk/cmd/internal/service"
+	"github.com/lewvy/gopk/internal/service"
 	"github.com/spf13/cobra"
 )
 
@@ -4,15 +4,16 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/lewvy/gopk/cmd/internal/data"
-	"github.com/lewvy/gopk/cmd/internal/service"
+	"github.com/lewvy/gopk/internal/data"
+	"github.com/lewvy/gopk/internal/service"
 	"github.com/spf13/cobra"
 )
 
 // rmCmd represents the rm command
 var rmCmd = &cobra.Command{
-	Use:   "rm",
-	Short: "Remove packages or entire groups from your local database",
+	Use:          "rm",
+	Short:        "Remove packages or entire groups from your local database",
+	SilenceUsage: true,
 	Long: `The rm command allows you to delete specific packages or an entire group of packages.
 By default, this performs a soft-delete to maintain sync compatibility.
 
@@ -37,11 +38,13 @@ Examples:
 
 		// 2. Handle Individual Package Deletion
 		if len(pkgs) > 0 {
-			err := service.DeletePackage(ctx, queries, pkgs)
+			n, err := service.DeletePackage(ctx, queries, pkgs)
+			if n > 0 {
+				fmt.Printf("Successfully marked %d package(s) as deleted\n", n)
+			}
 			if err != nil {
 				return fmt.Errorf("failed to delete packages: %w", err)
 			}
-			fmt.Printf("Successfully marked %d package(s) as deleted\n", len(pkgs))
 		}
 
 		// Validation: If no flags were provided
@@ -56,6 +59,8 @@ Examples:
 func init() {
 	rmCmd.Flags().StringP("group", "g", "", "name of the group to delete")
 	rmCmd.Flags().StringSliceP("names", "n", []string{}, "list the packages to delete")
+	rmCmd.RegisterFlagCompletionFunc("group", completeGroup)
+	rmCmd.RegisterFlagCompletionFunc("names", completeAliasList)
 	rootCmd.AddCommand(rmCmd)
 
 	// Here you will define your flags and configuration settings.
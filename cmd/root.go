@@ -4,28 +4,36 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
-	"database/sql"
 	"log"
 	"os"
 
-	"github.com/lewvy/gopk/cmd/internal/data"
 	"github.com/lewvy/gopk/cmd/tui"
 	"github.com/lewvy/gopk/config"
+	"github.com/lewvy/gopk/internal/data"
+	"github.com/lewvy/gopk/internal/store"
+	"github.com/lewvy/gopk/pkg/gopk"
 	"github.com/spf13/cobra"
 )
 
-var queries *data.Queries
-var DB *sql.DB
+// queries serves the commands that need more than the public API offers;
+// the others go through registry, which shares its store.
+var queries data.Querier
+var registry *gopk.Registry
 
 var rootCmd = &cobra.Command{
 	Use: "gopk",
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		DB, err := config.InitDB()
+		if profile, _ := cmd.Flags().GetString("profile"); profile != "" {
+			config.SetProfile(profile)
+		}
+
+		st, err := store.Open()
 		if err != nil {
 			log.Fatalf("error initializing db: %q", err)
 		}
 
-		queries = data.New(DB)
+		queries = st
+		registry = gopk.NewStore(st)
 
 	},
 	Short: "A brief description of your application",
@@ -36,7 +44,7 @@ Cobra is a CLI library for Go that empowers applications.
 This application is a tool to generate the needed files
 to quickly create a Cobra application.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return tui.Start(queries)
+		return tui.Start(queries, registry)
 	},
 }
 
@@ -48,5 +56,7 @@ func Execute() {
 }
 
 func init() {
+	rootCmd.PersistentFlags().String("profile", "", "registry profile to use (default $GOPK_PROFILE or \"default\")")
+	rootCmd.PersistentFlags().StringP("output", "o", outputTable, "output format of listings: table, json, jsonl, tsv or template=<text/template>")
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+
+	"github.com/lewvy/gopk/pkg/gopk"
+	"github.com/spf13/cobra"
+)
+
+var searchCmd = &cobra.Command{
+	Use:          "search <query>",
+	Short:        "Search saved packages",
+	SilenceUsage: true,
+	Long: `Search the packages saved in your gopk registry.
+
+The query is matched fuzzily against the alias and URL of each package,
+as in the TUI search, so "zlg" finds zerolog. Packages whose description
+recorded by 'gopk enrich' contains every word of the query are listed
+after those matches.
+
+Examples:
+  gopk search log
+  gopk search structured logging
+  gopk search zap -o 'template={{.Name}}'`,
+
+	Args: cobra.MinimumNArgs(1),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pkgs, err := registry.Search(context.Background(), strings.Join(args, " "))
+		if err != nil {
+			return err
+		}
+		if len(pkgs) == 0 && tableOutput(cmd) {
+			cmd.Println("No packages match.")
+			return nil
+		}
+		return writeListing(cmd, pkgs, []column[gopk.Package]{
+			{"NAME", func(p gopk.Package) string { return p.Name }},
+			{"URL", func(p gopk.Package) string { return p.URL }},
+			{"VERSION", func(p gopk.Package) string { return p.Version }},
+			{"SYNOPSIS", func(p gopk.Package) string { return p.Synopsis }},
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+}
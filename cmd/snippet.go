@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lewvy/gopk/internal/service"
+	"github.com/spf13/cobra"
+)
+
+var snippetCmd = &cobra.Command{
+	Use:          "snippet <alias> [name]",
+	Short:        "Show the code snippets saved for a package",
+	SilenceUsage: true,
+	Long: `Show Go snippets attached to a package in your gopk registry.
+
+'gopk snippet <alias>' is short for 'gopk snippet list <alias>'. Use the
+long form for packages saved under the alias add, rm or list.
+
+Examples:
+  gopk snippet zap
+  gopk snippet list zap production
+  gopk snippet add zap production setup.go
+  gopk snippet rm zap production`,
+
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: completeAlias,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return snippetListCmd.RunE(cmd, args)
+	},
+}
+
+var snippetListCmd = &cobra.Command{
+	Use:          "list <alias> [name]",
+	Short:        "Show the code snippets saved for a package",
+	SilenceUsage: true,
+	Long: `Show Go snippets attached to a package in your gopk registry.
+
+Without a name, every snippet of the package is printed under a comment
+header. With a name, only the body of that snippet is printed, which is
+convenient for editor integrations.`,
+
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: completeAlias,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		out := cmd.OutOrStdout()
+
+		if len(args) == 2 {
+			snippet, err := service.GetSnippet(ctx, queries, args[0], args[1])
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(out, snippet.Body)
+			return nil
+		}
+
+		snippets, err := service.ListSnippets(ctx, queries, args[0])
+		if err != nil {
+			return err
+		}
+		if len(snippets) == 0 {
+			return fmt.Errorf("no snippets saved for %s", args[0])
+		}
+		for i, s := range snippets {
+			if i > 0 {
+				fmt.Fprintln(out)
+			}
+			fmt.Fprintf(out, "// %s\n%s\n", s.Name, s.Body)
+		}
+		return nil
+	},
+}
+
+var snippetAddCmd = &cobra.Command{
+	Use:          "add <alias> <name> [file]",
+	Short:        "Attach a snippet to a package",
+	SilenceUsage: true,
+	Long: `Attach a named Go snippet to a package, replacing any snippet with the
+same name. The snippet is read from file, or from stdin when no file is
+given, and must be valid Go statements or declarations.`,
+
+	Args:              cobra.RangeArgs(2, 3),
+	ValidArgsFunction: completeAlias,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var body []byte
+		var err error
+		if len(args) == 3 {
+			body, err = os.ReadFile(args[2])
+		} else {
+			body, err = io.ReadAll(cmd.InOrStdin())
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := service.AddSnippet(context.Background(), queries, args[0], args[1], string(body)); err != nil {
+			return err
+		}
+		fmt.Printf("Saved snippet %q for %s\n", args[1], args[0])
+		return nil
+	},
+}
+
+var snippetRmCmd = &cobra.Command{
+	Use:               "rm <alias> <name>",
+	Short:             "Remove a snippet from a package",
+	SilenceUsage:      true,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeAlias,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := service.DeleteSnippet(context.Background(), queries, args[0], args[1]); err != nil {
+			return err
+		}
+		fmt.Printf("Removed snippet %q from %s\n", args[1], args[0])
+		return nil
+	},
+}
+
+func init() {
+	snippetCmd.AddCommand(snippetListCmd)
+	snippetCmd.AddCommand(snippetAddCmd)
+	snippetCmd.AddCommand(snippetRmCmd)
+
+	rootCmd.AddCommand(snippetCmd)
+}
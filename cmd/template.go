@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lewvy/gopk/internal/service"
+	"github.com/spf13/cobra"
+)
+
+var templateCmd = &cobra.Command{
+	Use:          "template",
+	Short:        "Manage project templates",
+	SilenceUsage: true,
+	Long: `Manage the project templates used by 'gopk new'.
+
+A template is a set of files, the packages they need and commands to run
+once they are installed. Templates are kept in the templates directory
+under the gopk config directory, one directory per template holding a
+template.toml manifest and a files directory, and are included in
+'gopk export' and 'gopk apply'.
+
+Examples:
+  gopk template add cli ./skeleton -p cobra,viper -c "go mod tidy"
+  gopk template list
+  gopk template show cli
+  gopk template rm cli`,
+
+	Args: cobra.NoArgs,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return templateListCmd.RunE(cmd, args)
+	},
+}
+
+var templateListCmd = &cobra.Command{
+	Use:          "list",
+	Short:        "List the saved project templates",
+	SilenceUsage: true,
+	Args:         cobra.NoArgs,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		templates, err := service.ListTemplates()
+		if err != nil {
+			return err
+		}
+		if len(templates) == 0 && tableOutput(cmd) {
+			cmd.Println("No templates saved; add one with 'gopk template add'.")
+			return nil
+		}
+
+		rows := make([]service.FileTemplate, len(templates))
+		for i, t := range templates {
+			rows[i] = t.FileTemplate()
+		}
+		return writeListing(cmd, rows, []column[service.FileTemplate]{
+			{"NAME", func(t service.FileTemplate) string { return t.Name }},
+			{"FILES", func(t service.FileTemplate) string { return strconv.Itoa(len(t.Files)) }},
+			{"PACKAGES", func(t service.FileTemplate) string { return strings.Join(t.Packages, ",") }},
+			{"DESCRIPTION", func(t service.FileTemplate) string { return t.Description }},
+		})
+	},
+}
+
+var templateShowCmd = &cobra.Command{
+	Use:               "show <name>",
+	Short:             "Show a project template",
+	SilenceUsage:      true,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeTemplate,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		t, err := service.LoadTemplate(args[0])
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "Template %s\n", t.Name)
+		if t.Description != "" {
+			fmt.Fprintf(out, "  %s\n", t.Description)
+		}
+		if len(t.Packages) > 0 {
+			fmt.Fprintf(out, "Packages: %s\n", strings.Join(t.Packages, ", "))
+		}
+		for _, c := range t.Commands {
+			fmt.Fprintf(out, "Command:  %s\n", c)
+		}
+		for _, f := range t.Files {
+			fmt.Fprintf(out, "\n// %s\n%s", f.Path, f.Body)
+			if !strings.HasSuffix(f.Body, "\n") {
+				fmt.Fprintln(out)
+			}
+		}
+		return nil
+	},
+}
+
+var templateAddCmd = &cobra.Command{
+	Use:          "add <name> <dir>",
+	Short:        "Save a directory as a project template",
+	SilenceUsage: true,
+	Long: `Save the files below dir as a project template, replacing any template
+with the same name. Version control directories are skipped.
+
+--packages lists the aliases of the saved packages to install into new
+projects, and --command the commands to run afterwards; it can be
+repeated.`,
+
+	Args: cobra.ExactArgs(2),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		packages, _ := cmd.Flags().GetStringSlice("packages")
+		commands, _ := cmd.Flags().GetStringArray("command")
+		description, _ := cmd.Flags().GetString("description")
+
+		files, err := service.ReadTemplateDir(args[1])
+		if err != nil {
+			return err
+		}
+		t := service.Template{
+			Name:        args[0],
+			Description: description,
+			Packages:    packages,
+			Commands:    commands,
+			Files:       files,
+		}
+		if err := service.SaveTemplate(t); err != nil {
+			return err
+		}
+		cmd.Printf("Saved template %s with %d files\n", t.Name, len(t.Files))
+		return nil
+	},
+}
+
+var templateRmCmd = &cobra.Command{
+	Use:               "rm <name>",
+	Short:             "Remove a project template",
+	SilenceUsage:      true,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeTemplate,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := service.DeleteTemplate(args[0]); err != nil {
+			return err
+		}
+		cmd.Printf("Removed template %s\n", args[0])
+		return nil
+	},
+}
+
+func init() {
+	templateAddCmd.Flags().StringSliceP("packages", "p", nil, "aliases of the packages to install, comma-separated")
+	templateAddCmd.Flags().StringArrayP("command", "c", nil, "command to run in new projects (repeatable)")
+	templateAddCmd.Flags().String("description", "", "short description of the template")
+
+	templateCmd.AddCommand(templateListCmd)
+	templateCmd.AddCommand(templateShowCmd)
+	templateCmd.AddCommand(templateAddCmd)
+	templateCmd.AddCommand(templateRmCmd)
+
+	rootCmd.AddCommand(templateCmd)
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lewvy/gopk/internal/service"
+	"github.com/spf13/cobra"
+)
+
+var toolsCmd = &cobra.Command{
+	Use:          "tools",
+	Short:        "Compare installed tool binaries with the registry",
+	SilenceUsage: true,
+	Long: `List the tools saved in your gopk registry and the binaries installed
+for them with 'go install'.
+
+Binaries are read from GOBIN, or $GOPATH/bin when GOBIN is not set. The
+build info embedded in each binary identifies the package and module
+version it was built from, which is matched against the saved import
+path and version. Each tool is reported as:
+
+  installed  the binary is at the saved version (any version for latest)
+  missing    no binary was built from the saved package
+  drifted    the binary is at another version than the saved one
+
+Run 'gopk tools sync' to install missing and drifted tools.`,
+
+	Args: cobra.NoArgs,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tools, err := service.Tools(context.Background(), queries)
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		if len(tools) == 0 && tableOutput(cmd) {
+			fmt.Fprintln(out, "No tools saved. Save one with 'gopk add <path> --kind tool'.")
+			return nil
+		}
+
+		rows := make([]toolRow, len(tools))
+		for i, t := range tools {
+			rows[i] = toolRow{
+				Name:      t.Package.Name,
+				Package:   t.Package.Url,
+				Saved:     t.Package.Version.String,
+				Installed: t.Installed,
+				Binary:    t.Binary,
+				Status:    t.State.String(),
+			}
+		}
+		return writeListing(cmd, rows, []column[toolRow]{
+			{"NAME", func(r toolRow) string { return r.Name }},
+			{"PACKAGE", func(r toolRow) string { return r.Package }},
+			{"SAVED", func(r toolRow) string { return r.Saved }},
+			{"INSTALLED", func(r toolRow) string { return r.Installed }},
+			{"STATUS", func(r toolRow) string { return r.Status }},
+		})
+	},
+}
+
+// toolRow is a tool as printed by tools.
+type toolRow struct {
+	Name      string `json:"name"`
+	Package   string `json:"package"`
+	Saved     string `json:"saved"`
+	Installed string `json:"installed"`
+	Binary    string `json:"binary"`
+	Status    string `json:"status"`
+}
+
+var toolsSyncCmd = &cobra.Command{
+	Use:          "sync",
+	Short:        "Install missing tools and reinstall drifted ones",
+	SilenceUsage: true,
+	Long: `Run 'go install' for every saved tool that is missing or drifted, at
+its saved version. Tools saved as latest are only installed when missing.
+
+License and vulnerability checks apply as with 'gopk install'. Use
+--dry-run to print what would be installed.`,
+
+	Args: cobra.NoArgs,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		failOnVuln, _ := cmd.Flags().GetBool("fail-on-vuln")
+
+		pending, err := service.SyncTools(context.Background(), queries, dryRun, service.InstallOptions{FailOnVuln: failOnVuln})
+
+		out := cmd.OutOrStdout()
+		for _, t := range pending {
+			from := ""
+			if t.Installed != "" {
+				from = " from " + t.Installed
+			}
+			fmt.Fprintf(out, "%s: %s%s -> %s\n", t.Package.Name, t.State, from, service.ToolQuery(t.Package))
+		}
+		if err != nil {
+			return err
+		}
+		if len(pending) == 0 {
+			fmt.Fprintln(out, "All tools are installed.")
+		}
+		return nil
+	},
+}
+
+func init() {
+	toolsSyncCmd.Flags().Bool("dry-run", false, "print the tools to install without installing them")
+	toolsSyncCmd.Flags().Bool("fail-on-vuln", false, "do not install versions with known vulnerabilities")
+
+	toolsCmd.AddCommand(toolsSyncCmd)
+
+	rootCmd.AddCommand(toolsCmd)
+}
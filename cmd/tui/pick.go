@@ -0,0 +1,227 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lewvy/gopk/internal/data"
+	"github.com/sahilm/fuzzy"
+)
+
+// ErrPickCancelled is returned by Pick when the picker is closed without
+// choosing a package.
+var ErrPickCancelled = errors.New("nothing picked")
+
+// PickOptions control Pick.
+type PickOptions struct {
+	// Query is the initial search.
+	Query string
+
+	// Height is the number of packages shown at once; 10 when zero.
+	Height int
+}
+
+// pickModel is a compact fuzzy picker drawn below the cursor rather than
+// on the alternate screen, so that it fits in shell pipelines.
+type pickModel struct {
+	all     []data.Package
+	matches []data.Package
+	input   textinput.Model
+
+	cursor int
+	offset int
+	height int
+
+	// chosen holds the selected packages in the order they were selected.
+	chosen []data.Package
+
+	done      bool
+	cancelled bool
+}
+
+// Pick lets the user choose packages among pkgs with the same fuzzy
+// matching as the TUI search. tab toggles the package under the cursor and
+// enter accepts the selection, or the package under the cursor when none is
+// selected. The picker reads the terminal and draws on stderr, leaving
+// stdout to the caller.
+func Pick(pkgs []data.Package, opts PickOptions) ([]data.Package, error) {
+	if len(pkgs) == 0 {
+		return nil, errors.New("no packages saved")
+	}
+
+	in := textinput.New()
+	in.Prompt = "> "
+	in.Placeholder = "search packages"
+	in.PromptStyle = lipgloss.NewStyle().Foreground(colorPrimary)
+	in.SetValue(opts.Query)
+	in.Focus()
+
+	m := pickModel{all: pkgs, input: in, height: opts.Height}
+	if m.height <= 0 {
+		m.height = 10
+	}
+	m.filter()
+
+	final, err := tea.NewProgram(m, tea.WithOutput(os.Stderr), tea.WithInputTTY()).Run()
+	if err != nil {
+		return nil, err
+	}
+	m = final.(pickModel)
+	if m.cancelled {
+		return nil, ErrPickCancelled
+	}
+	if len(m.chosen) > 0 {
+		return m.chosen, nil
+	}
+	if len(m.matches) == 0 {
+		return nil, ErrPickCancelled
+	}
+	return []data.Package{m.matches[m.cursor]}, nil
+}
+
+func (m pickModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m pickModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok {
+		switch key.String() {
+		case "ctrl+c", "esc":
+			m.cancelled = true
+			return m, tea.Quit
+
+		case "enter":
+			m.done = true
+			return m, tea.Quit
+
+		case "up", "ctrl+p", "ctrl+k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+			m.scroll()
+			return m, nil
+
+		case "down", "ctrl+n", "ctrl+j":
+			if m.cursor < len(m.matches)-1 {
+				m.cursor++
+			}
+			m.scroll()
+			return m, nil
+
+		case "tab":
+			if len(m.matches) > 0 {
+				m.toggle(m.matches[m.cursor])
+				if m.cursor < len(m.matches)-1 {
+					m.cursor++
+				}
+				m.scroll()
+			}
+			return m, nil
+		}
+	}
+
+	query := m.input.Value()
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	if m.input.Value() != query {
+		m.filter()
+	}
+	return m, cmd
+}
+
+// filter matches the packages against the query, keeping the saved order
+// when the query is empty.
+func (m *pickModel) filter() {
+	query := m.input.Value()
+	if query == "" {
+		m.matches = m.all
+	} else {
+		m.matches = nil
+		for _, match := range fuzzy.FindFrom(query, packageSource(m.all)) {
+			m.matches = append(m.matches, m.all[match.Index])
+		}
+	}
+	m.cursor = 0
+	m.offset = 0
+}
+
+// scroll keeps the cursor inside the visible window.
+func (m *pickModel) scroll() {
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+m.height {
+		m.offset = m.cursor - m.height + 1
+	}
+}
+
+func (m *pickModel) toggle(pkg data.Package) {
+	for i, p := range m.chosen {
+		if p.ID == pkg.ID && p.Name == pkg.Name {
+			m.chosen = append(m.chosen[:i], m.chosen[i+1:]...)
+			return
+		}
+	}
+	m.chosen = append(m.chosen, pkg)
+}
+
+func (m pickModel) isChosen(pkg data.Package) bool {
+	for _, p := range m.chosen {
+		if p.ID == pkg.ID && p.Name == pkg.Name {
+			return true
+		}
+	}
+	return false
+}
+
+func (m pickModel) View() string {
+	// Clear the picker once it is closed, so that the terminal only shows
+	// what the caller prints.
+	if m.done || m.cancelled {
+		return ""
+	}
+
+	var s strings.Builder
+	s.WriteString(m.input.View())
+	s.WriteRune('\n')
+
+	dim := lipgloss.NewStyle().Foreground(colorSecondary)
+	count := fmt.Sprintf("  %d/%d", len(m.matches), len(m.all))
+	if len(m.chosen) > 0 {
+		count += fmt.Sprintf(" (%d selected)", len(m.chosen))
+	}
+	s.WriteString(dim.Render(count))
+	s.WriteRune('\n')
+
+	width := 0
+	for _, p := range m.matches {
+		width = max(width, len(p.Name))
+	}
+	width = min(width, 24)
+
+	cursorStyle := lipgloss.NewStyle().Background(colorCursorBg).Foreground(colorCursorFg)
+	nameStyle := lipgloss.NewStyle().Width(width + 2).Foreground(colorPrimary).Bold(true)
+	end := min(m.offset+m.height, len(m.matches))
+	for i := m.offset; i < end; i++ {
+		pkg := m.matches[i]
+		check := "[ ]"
+		if m.isChosen(pkg) {
+			check = lipgloss.NewStyle().Foreground(colorSelected).Render("[x]")
+		}
+		line := fmt.Sprintf("%s %s%s", check, nameStyle.Render(pkg.Name), dim.Render(pkg.Url))
+		if i == m.cursor {
+			s.WriteString(cursorStyle.Render("> " + line))
+		} else {
+			s.WriteString("  " + line)
+		}
+		s.WriteRune('\n')
+	}
+
+	s.WriteString(dim.Render("tab: select   enter: accept   esc: cancel"))
+	return s.String()
+}
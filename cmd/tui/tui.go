@@ -5,13 +5,17 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"sync"
 
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-	"github.com/lewvy/gopk/cmd/internal/data"
-	"github.com/lewvy/gopk/cmd/internal/service"
+	"github.com/lewvy/gopk/config"
+	"github.com/lewvy/gopk/internal/data"
+	"github.com/lewvy/gopk/internal/service"
+	"github.com/lewvy/gopk/internal/vulndb"
+	"github.com/lewvy/gopk/pkg/gopk"
 	"github.com/sahilm/fuzzy"
 )
 
@@ -21,6 +25,7 @@ var (
 	colorSelected  = lipgloss.Color("151")
 	colorCursorBg  = lipgloss.Color("236")
 	colorCursorFg  = lipgloss.Color("255")
+	colorDanger    = lipgloss.Color("196")
 )
 
 type installFinishedMsg struct {
@@ -29,14 +34,28 @@ type installFinishedMsg struct {
 }
 
 type packageAddedMsg struct {
+	pkg gopk.Package
 	err error
 }
 
+// aliasProposalMsg is the alias proposed for url in the add form.
+type aliasProposalMsg struct {
+	url      string
+	proposal service.AliasProposal
+	err      error
+}
+
 type groupCreatedMsg struct {
 	name string
 	err  error
 }
 
+type projectCreatedMsg struct {
+	module string
+	dir    string
+	err    error
+}
+
 type groupAssignedMsg struct {
 	group string
 	count int
@@ -56,6 +75,12 @@ type packagesListMsg struct {
 	packages []data.Package
 }
 
+type snippetsListMsg struct {
+	pkg      data.Package
+	snippets []data.Snippet
+	err      error
+}
+
 type viewMode int
 type sortMode int
 
@@ -81,7 +106,8 @@ type model struct {
 	choices  []data.Package
 	filtered []data.Package
 	selected map[data.Package]struct{}
-	queries  *data.Queries
+	queries  data.Querier
+	registry *gopk.Registry
 	spinner  spinner.Model
 	view     viewMode
 
@@ -89,11 +115,12 @@ type model struct {
 
 	activeGroup data.Group
 
-	installing    bool
-	adding        bool
-	searching     bool
-	assigning     bool
-	creatingGroup bool
+	installing      bool
+	adding          bool
+	searching       bool
+	assigning       bool
+	creatingGroup   bool
+	creatingProject bool
 
 	statusMessage string
 	sm            sortMode
@@ -104,6 +131,12 @@ type model struct {
 	searchInput textinput.Model
 	groupInput  textinput.Model
 
+	// projectInputs are the template and module path of the new project
+	// form of the group view, which installs projectGroup.
+	projectInputs []textinput.Model
+	projectFocus  int
+	projectGroup  string
+
 	groups []data.Group
 
 	cursorGroup   int
@@ -111,20 +144,42 @@ type model struct {
 
 	installFlag bool
 	forceFlag   bool
+
+	// proposal is the alias proposed for proposalURL, the URL of the add
+	// form, shown while the name is left empty.
+	proposal    aliasProposalMsg
+	proposalURL string
+
+	showDetail bool
+	snippets   []data.Snippet
+
+	info map[int64]data.PackageInfo
+
+	// vulns is the configured vulnerability database, or nil.
+	vulns *vulndb.DB
+
+	// layers are the read-only registries shown below personal packages.
+	layers service.Layers
 }
 
-func initialModel(q *data.Queries) model {
-	packages, err := service.List(q, -1, false)
+func initialModel(q data.Querier, reg *gopk.Registry, vulns *vulndb.DB, layers service.Layers) model {
+	packages, err := service.List(context.Background(), q, -1, false)
 	if err != nil {
 		log.Printf("error retrieving packages: %v", err)
 		packages = []data.Package{}
 	}
 
+	info, err := service.PackageInfo(context.Background(), q)
+	if err != nil {
+		log.Printf("error retrieving package info: %v", err)
+		info = map[int64]data.PackageInfo{}
+	}
+
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = lipgloss.NewStyle().Foreground(colorPrimary)
 
-	inputs := make([]textinput.Model, 3)
+	inputs := make([]textinput.Model, 4)
 
 	inputs[0] = textinput.New()
 	inputs[0].Placeholder = "URL (e.g. github.com/charmbracelet/log)"
@@ -143,6 +198,11 @@ func initialModel(q *data.Queries) model {
 	inputs[2].CharLimit = 20
 	inputs[2].Width = 50
 
+	inputs[3] = textinput.New()
+	inputs[3].Placeholder = "Module (optional, inferred when empty)"
+	inputs[3].CharLimit = 156
+	inputs[3].Width = 50
+
 	si := textinput.New()
 	si.Placeholder = "Search packages..."
 	si.CharLimit = 50
@@ -155,6 +215,20 @@ func initialModel(q *data.Queries) model {
 	gi.Width = 30
 	gi.Prompt = "Name: "
 
+	projectInputs := make([]textinput.Model, 2)
+
+	projectInputs[0] = textinput.New()
+	projectInputs[0].Placeholder = "Template (optional)"
+	projectInputs[0].CharLimit = 50
+	projectInputs[0].Width = 50
+	projectInputs[0].Prompt = "Template: "
+
+	projectInputs[1] = textinput.New()
+	projectInputs[1].Placeholder = "Module path (e.g. github.com/me/svc)"
+	projectInputs[1].CharLimit = 156
+	projectInputs[1].Width = 50
+	projectInputs[1].Prompt = "Module:   "
+
 	return model{
 		choices:       packages,
 		filtered:      packages,
@@ -165,6 +239,7 @@ func initialModel(q *data.Queries) model {
 		inputs:        inputs,
 		searchInput:   si,
 		groupInput:    gi,
+		projectInputs: projectInputs,
 		focusIndex:    0,
 		installFlag:   false,
 		adding:        false,
@@ -172,7 +247,11 @@ func initialModel(q *data.Queries) model {
 		assigning:     false,
 		creatingGroup: false,
 		queries:       q,
+		registry:      reg,
 		groups:        []data.Group{},
+		info:          info,
+		vulns:         vulns,
+		layers:        layers,
 	}
 }
 
@@ -193,7 +272,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			var cmd tea.Cmd
 			m.spinner, cmd = m.spinner.Update(msg)
 			return m, cmd
-		case installFinishedMsg, installGroupMsg:
+		case installFinishedMsg, installGroupMsg, projectCreatedMsg:
 		default:
 			return m, nil
 		}
@@ -202,6 +281,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if m.creatingGroup {
 		return m.creatingGroupUpdate(msg)
 	}
+	if m.creatingProject {
+		return m.creatingProjectUpdate(msg)
+	}
 	if m.assigning {
 		return m.assigningUpdate(msg)
 	}
@@ -284,6 +366,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if m.cursorPackage > 0 {
 					m.cursorPackage--
 				}
+				if m.showDetail {
+					return m, m.fetchSnippetsCmd()
+				}
 
 			}
 
@@ -298,8 +383,21 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.cursorPackage++
 
 				}
+				if m.showDetail {
+					return m, m.fetchSnippetsCmd()
+				}
 			}
 
+		case "s":
+			if m.view == groupView {
+				return m, nil
+			}
+			m.showDetail = !m.showDetail
+			if m.showDetail {
+				return m, m.fetchSnippetsCmd()
+			}
+			return m, nil
+
 		case "+":
 			m.adding = true
 			m.resetForm()
@@ -311,6 +409,11 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.groupInput.Focus()
 			return m, textinput.Blink
 
+		case "n":
+			if m.view == groupView {
+				return m.startProjectForm()
+			}
+
 		case "a":
 			if len(m.selected) > 0 {
 				m.assigning = true
@@ -339,7 +442,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					pkgs = append(pkgs, i.Name)
 				}
 
-				if err := service.DeletePackage(context.Background(), m.queries, pkgs); err != nil {
+				if _, err := service.DeletePackage(context.Background(), m.queries, pkgs); err != nil {
 					m.err = err
 					return m, nil
 				}
@@ -354,18 +457,18 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.installing = true
 				m.statusMessage = ""
 				g := m.groups[m.cursorGroup]
-				return m, tea.Batch(installGroupCmd(context.Background(), m.queries, g.Name), m.spinner.Tick)
+				return m, tea.Batch(installGroupCmd(context.Background(), m.registry, g.Name), m.spinner.Tick)
 
 			default:
 				if len(m.selected) > 0 {
 					m.installing = true
 					m.statusMessage = ""
-					pkgs := make([]string, 0, len(m.selected))
+					pkgs := make([]data.Package, 0, len(m.selected))
 					for pkg := range m.selected {
-						pkgs = append(pkgs, pkg.Url)
+						pkgs = append(pkgs, pkg)
 					}
 					m.selected = make(map[data.Package]struct{})
-					return m, tea.Batch(installPackagesCmd(pkgs), m.spinner.Tick)
+					return m, tea.Batch(installPackagesCmd(m.registry, pkgs), m.spinner.Tick)
 				}
 
 			}
@@ -421,14 +524,15 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		} else {
 			m.statusMessage = "Group installed successfully"
 		}
+		m.statusMessage += warningsText()
 		m.installing = false
 		return m, nil
 	case installFinishedMsg:
 		m.installing = false
 		if msg.err != nil {
-			m.statusMessage = "Error: " + msg.err.Error()
+			m.statusMessage = "Error: " + msg.err.Error() + warningsText()
 		} else {
-			m.statusMessage = "Installation complete!"
+			m.statusMessage = "Installation complete!" + warningsText()
 			return m, updateStatsCmd(m.queries, msg.installedUrls)
 		}
 	case sortGroupByFreqMsg:
@@ -455,7 +559,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if msg.err != nil {
 			m.statusMessage = "Error adding: " + msg.err.Error()
 		} else {
-			m.statusMessage = "Package added successfully!"
+			m.statusMessage = fmt.Sprintf("Added %s as %s", msg.pkg.URL, msg.pkg.Name) + warningsText()
 			return m, refreshListCmd(m.queries, m.sm)
 		}
 
@@ -476,6 +580,16 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, fetchGroupsCmd(m.queries)
 		}
 
+	case projectCreatedMsg:
+		m.installing = false
+		if msg.err != nil {
+			m.statusMessage = "Error creating project: " + msg.err.Error()
+		} else {
+			m.statusMessage = "Created " + msg.module + " in " + msg.dir
+		}
+		m.statusMessage += warningsText()
+		return m, nil
+
 	case groupAssignedMsg:
 		if msg.err != nil {
 			m.statusMessage = "Error assigning: " + msg.err.Error()
@@ -491,6 +605,13 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.groups = msg.groups
 		}
 
+	case snippetsListMsg:
+		if msg.err != nil {
+			m.statusMessage = "Error fetching snippets: " + msg.err.Error()
+		} else if len(m.filtered) > 0 && m.filtered[m.cursorPackage].ID == msg.pkg.ID {
+			m.snippets = msg.snippets
+		}
+
 	case packagesListMsg:
 		m.choices = msg.packages
 		if m.searchInput.Value() != "" {
@@ -520,10 +641,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-func installGroupCmd(ctx context.Context, queries *data.Queries, groupName string) tea.Cmd {
+func installGroupCmd(ctx context.Context, reg *gopk.Registry, groupName string) tea.Cmd {
 	return func() tea.Msg {
 		return installGroupMsg{
-			err: service.InstallGroup(ctx, queries, groupName),
+			err: reg.InstallGroup(ctx, groupName, gopk.InstallOptions{}),
 		}
 	}
 }
@@ -532,7 +653,7 @@ type installGroupMsg struct {
 	err error
 }
 
-func sortGroupByLastUsed(context context.Context, queries *data.Queries, groupName string) tea.Cmd {
+func sortGroupByLastUsed(context context.Context, queries data.Querier, groupName string) tea.Cmd {
 	return func() tea.Msg {
 		pkgs, err := service.ListPackagesByGroupOrderByLU(context, queries, groupName)
 		return sortGroupByLastUsedMsg{
@@ -547,7 +668,7 @@ type sortGroupByLastUsedMsg struct {
 	err  error
 }
 
-func sortGroupByFreq(context context.Context, queries *data.Queries, groupName string) tea.Cmd {
+func sortGroupByFreq(context context.Context, queries data.Querier, groupName string) tea.Cmd {
 	return func() tea.Msg {
 		pkgs, err := service.ListPackagesByGroupOrderByFreq(context, queries, groupName)
 		return sortGroupByFreqMsg{pkgs, err}
@@ -559,14 +680,14 @@ type sortGroupByFreqMsg struct {
 	err  error
 }
 
-func removePackagesFromGroups(queries *data.Queries, pkgs map[data.Package]struct{}, group data.Group) tea.Cmd {
+func removePackagesFromGroups(queries data.Querier, pkgs map[data.Package]struct{}, group data.Group) tea.Cmd {
 	return func() tea.Msg {
 		err := service.RemovePackagesFromGroups(context.Background(), queries, pkgs, group.ID)
 		return packagesRemovedMsg{err: err}
 	}
 }
 
-func fetchPackagesByGroupCmd(q *data.Queries, group string) tea.Cmd {
+func fetchPackagesByGroupCmd(q data.Querier, group string) tea.Cmd {
 	return func() tea.Msg {
 		pkgs, err := service.ListPackagesByGroupOrderByFreq(context.Background(), q, group)
 		if err != nil {
@@ -610,6 +731,12 @@ func (m model) searchingUpdate(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 func (m model) addingUpdate(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case aliasProposalMsg:
+		if msg.url == m.proposalURL {
+			m.proposal = msg
+		}
+		return m, nil
+
 	case tea.KeyMsg:
 		switch msg.String() {
 
@@ -636,6 +763,7 @@ func (m model) addingUpdate(msg tea.Msg) (tea.Model, tea.Cmd) {
 				url := m.inputs[0].Value()
 				name := m.inputs[1].Value()
 				version := m.inputs[2].Value()
+				module := m.inputs[3].Value()
 
 				if url == "" {
 					return m, nil
@@ -644,7 +772,7 @@ func (m model) addingUpdate(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.adding = false
 				m.statusMessage = "Adding " + url + "..."
 				m.resetForm()
-				return m, addPackageCmd(m.queries, url, name, version, m.installFlag, m.forceFlag)
+				return m, addPackageCmd(m.registry, url, module, name, version, m.installFlag, m.forceFlag)
 			}
 			m.focusIndex++
 			m.updateFocus()
@@ -669,6 +797,13 @@ func (m model) addingUpdate(msg tea.Msg) (tea.Model, tea.Cmd) {
 	for i := range m.inputs {
 		m.inputs[i], cmds[i] = m.inputs[i].Update(msg)
 	}
+	if url := strings.TrimSpace(m.inputs[0].Value()); url != m.proposalURL {
+		m.proposalURL = url
+		m.proposal = aliasProposalMsg{}
+		if url != "" {
+			cmds = append(cmds, proposeAliasCmd(m.queries, url))
+		}
+	}
 	return m, tea.Batch(cmds...)
 }
 
@@ -739,12 +874,89 @@ func (m model) creatingGroupUpdate(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// startProjectForm opens the new project form for the selected group,
+// prefilled with the template named after the group if there is one.
+func (m model) startProjectForm() (tea.Model, tea.Cmd) {
+	if len(m.groups) == 0 {
+		m.statusMessage = "No group selected; create one with 'c'."
+		return m, nil
+	}
+	group := m.groups[m.cursorGroup].Name
+
+	templates, err := service.ListTemplates()
+	if err != nil {
+		m.statusMessage = "Error listing templates: " + err.Error()
+		return m, nil
+	}
+
+	for i := range m.projectInputs {
+		m.projectInputs[i].Reset()
+	}
+	for _, t := range templates {
+		if t.Name == group {
+			m.projectInputs[0].SetValue(t.Name)
+		}
+	}
+	m.projectGroup = group
+	m.projectFocus = 1
+	m.updateProjectFocus()
+	m.creatingProject = true
+	return m, textinput.Blink
+}
+
+func (m *model) updateProjectFocus() {
+	for i := range m.projectInputs {
+		if i == m.projectFocus {
+			m.projectInputs[i].Focus()
+		} else {
+			m.projectInputs[i].Blur()
+		}
+	}
+}
+
+func (m model) creatingProjectUpdate(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "tab", "shift+tab", "up", "down":
+			m.projectFocus = 1 - m.projectFocus
+			m.updateProjectFocus()
+			return m, nil
+
+		case "enter":
+			name := strings.TrimSpace(m.projectInputs[0].Value())
+			module := strings.TrimSpace(m.projectInputs[1].Value())
+			if module == "" {
+				m.projectFocus = 1
+				m.updateProjectFocus()
+				return m, nil
+			}
+
+			m.creatingProject = false
+			m.installing = true
+			m.statusMessage = ""
+			return m, tea.Batch(newProjectCmd(m.queries, name, m.projectGroup, module), m.spinner.Tick)
+
+		case "esc":
+			m.creatingProject = false
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.projectInputs[m.projectFocus], cmd = m.projectInputs[m.projectFocus].Update(msg)
+	return m, cmd
+}
+
 func (m model) View() string {
 	var s strings.Builder
 
 	if m.creatingGroup {
 		return m.createGroupView()
 	}
+	if m.creatingProject {
+		return m.newProjectView()
+	}
 	if m.adding {
 		return m.addPackageView()
 	}
@@ -754,13 +966,18 @@ func (m model) View() string {
 
 	switch m.view {
 	case packageView:
-		s.WriteString(m.packageView("GOPK"))
+		s.WriteString(m.packageView("GOPK " + m.profileView()))
 	case groupPackageView:
 		s.WriteString(m.packageView("Group: " + m.activeGroup.Name))
 	case groupView:
 		s.WriteString(m.groupListView())
 	}
 
+	if m.showDetail && m.view != groupView {
+		s.WriteString("\n")
+		s.WriteString(m.detailView())
+	}
+
 	if m.installing {
 		s.WriteString("\n")
 		s.WriteString(m.installingView())
@@ -817,6 +1034,37 @@ func (m model) groupListView() string {
 	return s.String()
 }
 
+func (m model) detailView() string {
+	var s strings.Builder
+
+	if len(m.filtered) == 0 {
+		return ""
+	}
+	pkg := m.filtered[m.cursorPackage]
+
+	title := lipgloss.NewStyle().Foreground(colorPrimary).Bold(true)
+	s.WriteString(title.Render("Snippets: " + pkg.Name))
+	s.WriteString("\n\n")
+
+	if len(m.snippets) == 0 {
+		s.WriteString(
+			lipgloss.NewStyle().
+				Foreground(colorSecondary).
+				Render("  No snippets. Add one with 'gopk snippet add " + pkg.Name + " <name>'."),
+		)
+		s.WriteRune('\n')
+		return s.String()
+	}
+
+	for _, snippet := range m.snippets {
+		s.WriteString(lipgloss.NewStyle().Foreground(colorSecondary).Render("// " + snippet.Name))
+		s.WriteRune('\n')
+		s.WriteString(snippet.Body)
+		s.WriteString("\n\n")
+	}
+	return s.String()
+}
+
 func (m model) installingView() string {
 	var s strings.Builder
 	fmt.Fprintf(&s, " %s Installing packages...\n\n", m.spinner.View())
@@ -850,6 +1098,19 @@ func (m model) addPackageView() string {
 		forceCheck = "[x]"
 	}
 
+	if m.inputs[1].Value() == "" && m.proposal.url != "" {
+		dim := lipgloss.NewStyle().Foreground(colorSecondary)
+		p := m.proposal.proposal
+		switch {
+		case m.proposal.err != nil:
+			s.WriteString("\n\n" + lipgloss.NewStyle().Foreground(colorDanger).Render("Alias: "+m.proposal.err.Error()))
+		case p.Collided():
+			fmt.Fprintf(&s, "\n\nAlias: %s %s", p.Alias, dim.Render(fmt.Sprintf("(%s is the alias of %s)", p.Base, p.Owner.Url)))
+		default:
+			fmt.Fprintf(&s, "\n\nAlias: %s", p.Alias)
+		}
+	}
+
 	fmt.Fprintf(&s, "\n\n%s Install immediately (ctrl+g)", installCheck)
 	fmt.Fprintf(&s, "\n%s Force update (ctrl+f)", forceCheck)
 	s.WriteString("\n\n(esc to cancel, enter to next/submit)")
@@ -882,6 +1143,22 @@ func (m model) createGroupView() string {
 	return s.String()
 }
 
+func (m model) newProjectView() string {
+	var s strings.Builder
+	s.WriteString("New Project with group " + m.projectGroup + "\n\n")
+	s.WriteString(m.projectInputs[0].View())
+	s.WriteRune('\n')
+	s.WriteString(m.projectInputs[1].View())
+	s.WriteString("\n\nThe project is created in a directory named after the module, with")
+	s.WriteString("\nthe packages of the group and, if one is given, the template's files.")
+	s.WriteString("\n\n(esc to cancel, tab to switch, enter to create)")
+	return s.String()
+}
+
+func (m model) profileView() string {
+	return lipgloss.NewStyle().Foreground(colorSecondary).Render("[" + config.ActiveProfile() + "]")
+}
+
 func (m model) packageView(title string) string {
 	var s strings.Builder
 
@@ -890,27 +1167,56 @@ func (m model) packageView(title string) string {
 
 	statusStyle := lipgloss.NewStyle().Width(6).PaddingRight(1)
 	nameStyle := lipgloss.NewStyle().
-		Width(30).
+		Width(20).
 		PaddingRight(2).
 		Foreground(colorPrimary).
 		Bold(true)
 
 	urlStyle := lipgloss.NewStyle().
-		Width(50).
+		Width(34).
+		PaddingRight(2).
+		Foreground(colorSecondary)
+
+	moduleStyle := lipgloss.NewStyle().
+		Width(28).
 		PaddingRight(2).
 		Foreground(colorSecondary)
 
+	kindStyle := lipgloss.NewStyle().
+		Width(8).
+		Foreground(colorSecondary)
+
 	freqStyle := lipgloss.NewStyle().
 		Width(6).
 		Align(lipgloss.Right).
 		Foreground(colorSecondary)
 
+	vulnStyle := lipgloss.NewStyle().
+		Width(6).
+		Align(lipgloss.Right).
+		Foreground(colorDanger)
+
+	layerStyle := lipgloss.NewStyle().
+		Width(12).
+		PaddingLeft(2).
+		Foreground(colorSecondary)
+
+	descStyle := lipgloss.NewStyle().
+		Width(36).
+		PaddingLeft(2).
+		Foreground(colorSecondary)
+
 	header := lipgloss.JoinHorizontal(
 		lipgloss.Left,
 		statusStyle.Render(""),
 		nameStyle.Render("PACKAGE"),
-		urlStyle.Render("REPOSITORY URL"),
+		urlStyle.Render("IMPORT PATH"),
+		moduleStyle.Render("MODULE"),
+		kindStyle.Render("KIND"),
 		freqStyle.Render("FREQ"),
+		vulnStyle.Render("VULN"),
+		layerStyle.Render("SOURCE"),
+		descStyle.Render("DESCRIPTION"),
 	)
 
 	s.WriteString(
@@ -944,20 +1250,25 @@ func (m model) packageView(title string) string {
 
 		status := fmt.Sprintf("%s [%s]", cursor, checked)
 
-		url := pkg.Url
-		if len(url) > 48 {
-			url = url[:45] + "..."
+		module := pkg.Module
+		if module == "" {
+			module = pkg.Url
 		}
 
 		row := lipgloss.JoinHorizontal(
 			lipgloss.Left,
 			statusStyle.Render(status),
 			nameStyle.Render(pkg.Name),
-			urlStyle.Render(url),
+			urlStyle.Render(truncate(pkg.Url, 32)),
+			moduleStyle.Render(truncate(module, 26)),
+			kindStyle.Render(service.KindOf(pkg)),
 			freqStyle.Render(fmt.Sprintf("%d", pkg.Freq.Int64)),
+			vulnStyle.Render(m.vulnMarker(pkg)),
+			layerStyle.Render(truncate(m.layers.Of(pkg), 10)),
+			descStyle.Render(truncate(m.info[pkg.ID].Synopsis, 34)),
 		)
 
-		rowStyle := lipgloss.NewStyle().Width(94)
+		rowStyle := lipgloss.NewStyle().Width(156)
 
 		if _, ok := m.selected[pkg]; ok {
 			rowStyle = rowStyle.Foreground(colorSelected)
@@ -978,7 +1289,27 @@ func (m model) packageView(title string) string {
 	return s.String()
 }
 
-func updateStatsCmd(q *data.Queries, urls []string) tea.Cmd {
+// vulnMarker returns the number of known vulnerabilities affecting the
+// saved version of pkg, or an empty string if there are none.
+func (m model) vulnMarker(pkg data.Package) string {
+	if m.vulns == nil {
+		return ""
+	}
+	found, err := service.PackageVulns(m.vulns, []data.Package{pkg})
+	if err != nil || len(found[pkg.ID]) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("!%d", len(found[pkg.ID]))
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n-3] + "..."
+	}
+	return s
+}
+
+func updateStatsCmd(q data.Querier, urls []string) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
 		for _, u := range urls {
@@ -1006,13 +1337,13 @@ func (m model) helpText() string {
 	switch m.view {
 
 	case packageView:
-		return "/: search	g: group   +: add   a: assign to group   c: create group   i: install   q: quit"
+		return "/: search	g: group   +: add   a: assign to group   c: create group   i: install   s: snippets   q: quit"
 
 	case groupView:
-		return "space/enter: open	i: install   c: create	esc/q: back"
+		return "space/enter: open	i: install   c: create   n: new project	esc/q: back"
 
 	case groupPackageView:
-		return "space: select   i: install   d: remove from group  esc/q: back"
+		return "space: select   i: install   d: remove from group   s: snippets  esc/q: back"
 
 	default:
 		return ""
@@ -1026,10 +1357,12 @@ func (m *model) resetForm() {
 	m.focusIndex = 0
 	m.installFlag = false
 	m.forceFlag = false
+	m.proposal = aliasProposalMsg{}
+	m.proposalURL = ""
 	m.updateFocus()
 }
 
-func deletePackageCmd(ctx context.Context, queries *data.Queries, group data.Group) tea.Cmd {
+func deletePackageCmd(ctx context.Context, queries data.Querier, group data.Group) tea.Cmd {
 	return func() tea.Msg {
 		if err := service.DeleteGroup(context.Background(), queries, group); err != nil {
 			return deletePackageMsg{
@@ -1049,56 +1382,112 @@ type deletePackageMsg struct {
 	msg string
 }
 
-func installPackagesCmd(pkgs []string) tea.Cmd {
+// installPackagesCmd adds pkgs to the module in the current directory, or
+// installs them as tools with 'go install' when there is no module.
+func installPackagesCmd(reg *gopk.Registry, pkgs []data.Package) tea.Cmd {
 	return func() tea.Msg {
-		err := service.GetFromUrl(pkgs)
+		urls := make([]string, 0, len(pkgs))
+		names := make([]string, 0, len(pkgs))
+		for _, pkg := range pkgs {
+			urls = append(urls, pkg.Url)
+			names = append(names, pkg.Name)
+		}
+		install := reg.Install
+		if !service.InModule(".") {
+			install = reg.InstallTools
+		}
+		err := install(context.Background(), names, gopk.InstallOptions{})
 		return installFinishedMsg{
 			err:           err,
-			installedUrls: pkgs,
+			installedUrls: urls,
+		}
+	}
+}
+
+func addPackageCmd(reg *gopk.Registry, url, module, name, version string, install, force bool) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		pkg, err := reg.Add(ctx, url, gopk.AddOptions{
+			Name:    name,
+			Module:  module,
+			Version: version,
+			Force:   force,
+		})
+		if err == nil && install {
+			err = reg.Install(ctx, []string{pkg.Name}, gopk.InstallOptions{})
 		}
+		return packageAddedMsg{pkg: pkg, err: err}
 	}
 }
 
-func addPackageCmd(q *data.Queries, url, name, version string, install, force bool) tea.Cmd {
+// proposeAliasCmd infers the alias the add form would save url under.
+func proposeAliasCmd(q data.Querier, url string) tea.Cmd {
 	return func() tea.Msg {
-		err := service.Add(url, name, version, install, force, q)
-		return packageAddedMsg{err: err}
+		p, err := service.InferAlias(context.Background(), q, url)
+		return aliasProposalMsg{url: url, proposal: p, err: err}
 	}
 }
 
-func createGroupCmd(q *data.Queries, name string) tea.Cmd {
+// newProjectCmd creates a module with the members of group in a directory
+// of the current directory named after the module, from the template saved
+// under name unless name is empty.
+func newProjectCmd(q data.Querier, name, group, module string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		dir := service.ProjectName(module)
+		var err error
+		if name == "" {
+			err = service.InitModule(ctx, q, dir, module, service.InitOptions{Group: group})
+		} else {
+			err = service.NewProject(ctx, q, name, dir, module, service.NewProjectOptions{Group: group})
+		}
+		return projectCreatedMsg{module: module, dir: dir, err: err}
+	}
+}
+
+func createGroupCmd(q data.Querier, name string) tea.Cmd {
 	return func() tea.Msg {
 		err := service.CreateGroup(q, name)
 		return groupCreatedMsg{name: name, err: err}
 	}
 }
 
-func assignToGroupCmd(q *data.Queries, pkgs []string, group string) tea.Cmd {
+func assignToGroupCmd(q data.Querier, pkgs []string, group string) tea.Cmd {
 	return func() tea.Msg {
 		err := service.AssignToGroup(q, pkgs, group)
 		return groupAssignedMsg{group: group, count: len(pkgs), err: err}
 	}
 }
 
-func fetchGroupsCmd(q *data.Queries) tea.Cmd {
+func (m model) fetchSnippetsCmd() tea.Cmd {
+	if len(m.filtered) == 0 {
+		return nil
+	}
+	pkg := m.filtered[m.cursorPackage]
+	q := m.queries
+	return func() tea.Msg {
+		snippets, err := q.ListSnippetsByPackage(context.Background(), pkg.ID)
+		return snippetsListMsg{pkg: pkg, snippets: snippets, err: err}
+	}
+}
+
+func fetchGroupsCmd(q data.Querier) tea.Cmd {
 	return func() tea.Msg {
-		groups, err := service.ListGroups(q)
+		groups, err := service.ListGroups(context.Background(), q)
 		return groupsListMsg{groups: groups, err: err}
 	}
 }
 
-func refreshListCmd(q *data.Queries, mode sortMode) tea.Cmd {
+func refreshListCmd(q data.Querier, mode sortMode) tea.Cmd {
 	return func() tea.Msg {
 		var pkgs []data.Package
 		var err error
 
 		switch mode {
 		case sortByFrequency:
-			pkgs, err = q.ListPackagesByFrequency(context.Background(), -1)
-		case sortByLastUsed:
-			pkgs, err = q.ListPackagesByLastUsed(context.Background(), -1)
+			pkgs, err = service.List(context.Background(), q, -1, true)
 		default:
-			pkgs, err = service.List(q, -1, false)
+			pkgs, err = service.List(context.Background(), q, -1, false)
 		}
 
 		if err != nil {
@@ -1109,9 +1498,52 @@ func refreshListCmd(q *data.Queries, mode sortMode) tea.Cmd {
 	}
 }
 
-func Start(q *data.Queries) error {
+// warnings collects messages from service.Warn while the TUI owns the
+// terminal, so they can be shown in the status line instead.
+var (
+	warningsMu sync.Mutex
+	warnings   []string
+)
+
+func collectWarning(msg string) {
+	warningsMu.Lock()
+	defer warningsMu.Unlock()
+	warnings = append(warnings, msg)
+}
+
+func warningsText() string {
+	warningsMu.Lock()
+	defer warningsMu.Unlock()
+	if len(warnings) == 0 {
+		return ""
+	}
+	text := "\nWarning: " + strings.Join(warnings, "\nWarning: ")
+	warnings = nil
+	return text
+}
+
+func Start(q data.Querier, reg *gopk.Registry) error {
+	service.Warn = collectWarning
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	vulns, err := service.OpenVulnDB(cfg)
+	if err != nil {
+		return err
+	}
+	if vulns != nil {
+		defer vulns.Close()
+	}
+
+	layers, err := service.ConfiguredLayers()
+	if err != nil {
+		return err
+	}
+
 	p := tea.NewProgram(
-		initialModel(q),
+		initialModel(q, reg, vulns, layers),
 		tea.WithAltScreen(),
 	)
 	if _, err := p.Run(); err != nil {
@@ -8,8 +8,8 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/lewvy/gopk/internal/sqlite"
 	migrations "github.com/lewvy/gopk/sql"
-	_ "github.com/mattn/go-sqlite3"
 	"github.com/pressly/goose/v3"
 )
 
@@ -30,12 +30,16 @@ func getDataDir() (string, error) {
 }
 
 func InitDB() (*sql.DB, error) {
-
-	path, err := getDataDir()
+	path, err := ProfileDir()
 	if err != nil {
-		return nil, fmt.Errorf("failed to determine data dir: %w", err)
+		return nil, err
 	}
 
+	return openDB(path)
+}
+
+func openDB(path string) (*sql.DB, error) {
+
 	if err := os.MkdirAll(path, 0700); err != nil {
 		return nil, fmt.Errorf("failed to create data dir: %w", err)
 	}
@@ -52,7 +56,7 @@ func InitDB() (*sql.DB, error) {
 		return nil, err
 	}
 
-	db, err := sql.Open("sqlite3", dbPath)
+	db, err := sql.Open(sqlite.Driver, dbPath)
 	if err != nil {
 		return nil, err
 	}
@@ -69,7 +73,7 @@ func InitDB() (*sql.DB, error) {
 }
 
 func ResetDB() error {
-	path, err := getDataDir()
+	path, err := profileDir(ActiveProfile())
 	if err != nil {
 		return err
 	}
@@ -82,7 +86,7 @@ func ResetDB() error {
 
 	goose.SetBaseFS(migrations.FS)
 
-	db, err := sql.Open("sqlite3", dbPath)
+	db, err := sql.Open(sqlite.Driver, dbPath)
 	if err != nil {
 		return err
 	}
@@ -104,13 +108,17 @@ func ResetDB() error {
 }
 
 func backupFile(src string) error {
+	return copyFile(src, src+".bak")
+}
+
+func copyFile(src, dst string) error {
 	source, err := os.Open(src)
 	if err != nil {
 		return err
 	}
 	defer source.Close()
 
-	dest, err := os.Create(src + ".bak")
+	dest, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,196 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// Config is the user configuration read from config.toml in the config
+// directory. Every field is optional.
+type Config struct {
+	License LicensePolicy `toml:"license"`
+	Vuln    VulnConfig    `toml:"vuln"`
+	Storage StorageConfig `toml:"storage"`
+	Go      GoConfig      `toml:"go"`
+	Alias   AliasConfig   `toml:"alias"`
+
+	// Layers are read-only registries stacked under the personal
+	// database, in order of precedence.
+	Layers []LayerConfig `toml:"layers"`
+}
+
+// LayerConfig is a read-only registry: either a gopkfile/export (JSON,
+// YAML or TOML) or another gopk SQLite database (.db, .sqlite).
+type LayerConfig struct {
+	Name string `toml:"name"`
+	Path string `toml:"path"`
+}
+
+// LicensePolicy restricts which module licenses may be installed. License
+// names are SPDX identifiers and may use wildcards, e.g. "BSD-*".
+type LicensePolicy struct {
+	// Allow lists the permitted licenses. When empty, every license that
+	// is not denied is permitted.
+	Allow []string `toml:"allow"`
+	Deny  []string `toml:"deny"`
+
+	// Mode is "warn" (the default) to print a warning before installing a
+	// disallowed package, or "block" to refuse the install.
+	Mode string `toml:"mode"`
+
+	// BlockUnknown treats licenses that could not be detected as
+	// disallowed. By default they only produce a warning.
+	BlockUnknown bool `toml:"block_unknown"`
+}
+
+// VulnConfig points gopk at a local copy of the Go vulnerability database.
+type VulnConfig struct {
+	// DB is a directory or zip file in the OSV format used by govulncheck.
+	// It can be overridden with $GOPK_VULNDB.
+	DB string `toml:"db"`
+
+	// FailOnVuln refuses to install package versions with known
+	// vulnerabilities instead of only warning about them.
+	FailOnVuln bool `toml:"fail_on_vuln"`
+}
+
+// StorageConfig selects where the registry is stored.
+type StorageConfig struct {
+	// Backend is "sqlite" (the default) or "json" for a plain JSON file
+	// that needs no database driver. It can be overridden with
+	// $GOPK_STORAGE.
+	Backend string `toml:"backend"`
+}
+
+// GoConfig selects the go toolchain gopk runs.
+type GoConfig struct {
+	// Binary is the go command to run, such as "go1.22.0" installed with
+	// golang.org/dl, or an absolute path. It defaults to "go" and can be
+	// overridden with $GOPK_GO.
+	Binary string `toml:"binary"`
+}
+
+// AliasConfig controls how aliases are inferred from import paths when a
+// package is added without a name.
+type AliasConfig struct {
+	// Conventions is "strip" (the default) to drop common decorations from
+	// the last path element: a "go-" prefix, a ".go" or "-go" suffix and
+	// the ".vN" version of gopkg.in paths. "keep" only drops /vN major
+	// version elements.
+	Conventions string `toml:"conventions"`
+
+	// Collision is "qualify" (the default) to propose an owner-qualified
+	// alias, such as go-chi/chi, when the inferred alias belongs to
+	// another package, or "error" to refuse to add the package.
+	Collision string `toml:"collision"`
+}
+
+func (p LicensePolicy) Enabled() bool {
+	return len(p.Allow) > 0 || len(p.Deny) > 0
+}
+
+func (p LicensePolicy) Blocking() bool {
+	return p.Mode == "block"
+}
+
+func getConfigDir() (string, error) {
+	if custom := os.Getenv("GOPK_CONFIG_DIR"); custom != "" {
+		return custom, nil
+	}
+
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "gopk"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "gopk"), nil
+}
+
+// TemplatesDir returns the directory holding project templates, one
+// subdirectory per template. It is shared by all profiles.
+func TemplatesDir() (string, error) {
+	dir, err := getConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine config dir: %w", err)
+	}
+	return filepath.Join(dir, "templates"), nil
+}
+
+// Load reads config.toml from the config directory. A missing file yields
+// the zero Config.
+func Load() (Config, error) {
+	var cfg Config
+
+	dir, err := getConfigDir()
+	if err != nil {
+		return cfg, fmt.Errorf("failed to determine config dir: %w", err)
+	}
+
+	path := filepath.Join(dir, "config.toml")
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return cfg, err
+	}
+
+	if err := toml.Unmarshal(content, &cfg); err != nil {
+		return cfg, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+
+	if db := os.Getenv("GOPK_VULNDB"); db != "" {
+		cfg.Vuln.DB = db
+	}
+	if backend := os.Getenv("GOPK_STORAGE"); backend != "" {
+		cfg.Storage.Backend = backend
+	}
+	if bin := os.Getenv("GOPK_GO"); bin != "" {
+		cfg.Go.Binary = bin
+	}
+
+	for i, l := range cfg.Layers {
+		if l.Path == "" {
+			return cfg, fmt.Errorf("invalid config %s: layers[%d] has no path", path, i)
+		}
+		if l.Name == "" {
+			cfg.Layers[i].Name = strings.TrimSuffix(filepath.Base(l.Path), filepath.Ext(l.Path))
+		}
+	}
+
+	switch cfg.License.Mode {
+	case "", "warn", "block":
+	default:
+		return cfg, fmt.Errorf("invalid config %s: license.mode must be \"warn\" or \"block\"", path)
+	}
+
+	switch cfg.Alias.Conventions {
+	case "":
+		cfg.Alias.Conventions = "strip"
+	case "strip", "keep":
+	default:
+		return cfg, fmt.Errorf("invalid config %s: alias.conventions must be \"strip\" or \"keep\"", path)
+	}
+	switch cfg.Alias.Collision {
+	case "":
+		cfg.Alias.Collision = "qualify"
+	case "qualify", "error":
+	default:
+		return cfg, fmt.Errorf("invalid config %s: alias.collision must be \"qualify\" or \"error\"", path)
+	}
+
+	switch cfg.Storage.Backend {
+	case "":
+		cfg.Storage.Backend = "sqlite"
+	case "sqlite", "json":
+	default:
+		return cfg, fmt.Errorf("invalid storage backend %q: must be \"sqlite\" or \"json\"", cfg.Storage.Backend)
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,207 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+// DefaultProfile keeps its database directly in the data directory, where
+// gopk stored it before profiles existed.
+const DefaultProfile = "default"
+
+var (
+	profileNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+	ErrProfileExists   = errors.New("profile already exists")
+	ErrProfileNotFound = errors.New("profile not found")
+)
+
+var profile string
+
+// SetProfile selects the profile used by InitDB, overriding $GOPK_PROFILE.
+func SetProfile(name string) {
+	profile = name
+}
+
+// ActiveProfile returns the profile set with SetProfile, then
+// $GOPK_PROFILE, then DefaultProfile.
+func ActiveProfile() string {
+	if profile != "" {
+		return profile
+	}
+	if env := os.Getenv("GOPK_PROFILE"); env != "" {
+		return env
+	}
+	return DefaultProfile
+}
+
+func ValidateProfileName(name string) error {
+	if !profileNameRe.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q: use letters, digits, '-' and '_'", name)
+	}
+	return nil
+}
+
+func profileDir(name string) (string, error) {
+	if err := ValidateProfileName(name); err != nil {
+		return "", err
+	}
+	base, err := getDataDir()
+	if err != nil {
+		return "", err
+	}
+	if name == DefaultProfile {
+		return base, nil
+	}
+	return filepath.Join(base, "profiles", name), nil
+}
+
+// ProfileDir returns the data directory of the active profile. Profiles
+// other than the default must have been created first.
+func ProfileDir() (string, error) {
+	name := ActiveProfile()
+	dir, err := profileDir(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to determine data dir: %w", err)
+	}
+
+	if name != DefaultProfile {
+		if ok, err := profileExists(name); err != nil {
+			return "", err
+		} else if !ok {
+			return "", fmt.Errorf("%w: %s (create it with `gopk profile create %s`)", ErrProfileNotFound, name, name)
+		}
+	}
+
+	return dir, nil
+}
+
+// profileFiles are the registry files a profile directory may hold, one
+// per storage backend.
+var profileFiles = []string{"packages.db", "packages.json"}
+
+func profileExists(name string) (bool, error) {
+	if name == DefaultProfile {
+		return true, nil
+	}
+	dir, err := profileDir(name)
+	if err != nil {
+		return false, err
+	}
+	info, err := os.Stat(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil && info.IsDir(), err
+}
+
+// ListProfiles returns the names of all profiles, sorted, with the default
+// profile first.
+func ListProfiles() ([]string, error) {
+	base, err := getDataDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(filepath.Join(base, "profiles"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	var others []string
+	for _, e := range entries {
+		if e.IsDir() && ValidateProfileName(e.Name()) == nil && e.Name() != DefaultProfile {
+			others = append(others, e.Name())
+		}
+	}
+	sort.Strings(others)
+
+	return append([]string{DefaultProfile}, others...), nil
+}
+
+// CreateProfile creates the data directory of a new profile. With the
+// SQLite backend, an empty migrated database is created in it.
+func CreateProfile(name string) error {
+	ok, err := profileExists(name)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return fmt.Errorf("%w: %s", ErrProfileExists, name)
+	}
+
+	dir, err := profileDir(name)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		return err
+	}
+	if cfg.Storage.Backend != "sqlite" {
+		return os.MkdirAll(dir, 0700)
+	}
+
+	db, err := openDB(dir)
+	if err != nil {
+		return err
+	}
+	return db.Close()
+}
+
+// CopyProfile creates dst with a copy of the registry files of src.
+func CopyProfile(src, dst string) error {
+	if ok, err := profileExists(src); err != nil {
+		return err
+	} else if !ok {
+		return fmt.Errorf("%w: %s", ErrProfileNotFound, src)
+	}
+	if ok, err := profileExists(dst); err != nil {
+		return err
+	} else if ok {
+		return fmt.Errorf("%w: %s", ErrProfileExists, dst)
+	}
+
+	srcDir, err := profileDir(src)
+	if err != nil {
+		return err
+	}
+	dstDir, err := profileDir(dst)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dstDir, 0700); err != nil {
+		return err
+	}
+
+	for _, name := range profileFiles {
+		err := copyFile(filepath.Join(srcDir, name), filepath.Join(dstDir, name))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteProfile removes the data directory of a profile. The default
+// profile cannot be deleted.
+func DeleteProfile(name string) error {
+	if name == DefaultProfile {
+		return errors.New("the default profile cannot be deleted")
+	}
+	if ok, err := profileExists(name); err != nil {
+		return err
+	} else if !ok {
+		return fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+	}
+
+	dir, err := profileDir(name)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
@@ -0,0 +1,178 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: aliases.sql
+
+package data
+
+import (
+	"context"
+	"strings"
+)
+
+const addPackageAlias = `-- name: AddPackageAlias :one
+INSERT INTO package_aliases (name, package_id)
+VALUES (?, ?)
+RETURNING id, name, package_id, created_at
+`
+
+type AddPackageAliasParams struct {
+	Name      string
+	PackageID int64
+}
+
+func (q *Queries) AddPackageAlias(ctx context.Context, arg AddPackageAliasParams) (PackageAlias, error) {
+	row := q.db.QueryRowContext(ctx, addPackageAlias, arg.Name, arg.PackageID)
+	var i PackageAlias
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.PackageID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deletePackageAlias = `-- name: DeletePackageAlias :execrows
+DELETE FROM package_aliases
+WHERE name = ?
+`
+
+func (q *Queries) DeletePackageAlias(ctx context.Context, name string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deletePackageAlias, name)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getPackageAlias = `-- name: GetPackageAlias :one
+SELECT id, name, package_id, created_at
+FROM package_aliases
+WHERE name = ?
+`
+
+func (q *Queries) GetPackageAlias(ctx context.Context, name string) (PackageAlias, error) {
+	row := q.db.QueryRowContext(ctx, getPackageAlias, name)
+	var i PackageAlias
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.PackageID,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getPackageAliasesByNames = `-- name: GetPackageAliasesByNames :many
+SELECT id, name, package_id, created_at
+FROM package_aliases
+WHERE name IN (/*SLICE:names*/?)
+`
+
+func (q *Queries) GetPackageAliasesByNames(ctx context.Context, names []string) ([]PackageAlias, error) {
+	query := getPackageAliasesByNames
+	var queryParams []interface{}
+	if len(names) > 0 {
+		for _, v := range names {
+			queryParams = append(queryParams, v)
+		}
+		query = strings.Replace(query, "/*SLICE:names*/?", strings.Repeat(",?", len(names))[1:], 1)
+	} else {
+		query = strings.Replace(query, "/*SLICE:names*/?", "NULL", 1)
+	}
+	rows, err := q.db.QueryContext(ctx, query, queryParams...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PackageAlias
+	for rows.Next() {
+		var i PackageAlias
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.PackageID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAliasesByPackage = `-- name: ListAliasesByPackage :many
+SELECT id, name, package_id, created_at
+FROM package_aliases
+WHERE package_id = ?
+ORDER BY name ASC
+`
+
+func (q *Queries) ListAliasesByPackage(ctx context.Context, packageID int64) ([]PackageAlias, error) {
+	rows, err := q.db.QueryContext(ctx, listAliasesByPackage, packageID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PackageAlias
+	for rows.Next() {
+		var i PackageAlias
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.PackageID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPackageAliases = `-- name: ListPackageAliases :many
+SELECT id, name, package_id, created_at
+FROM package_aliases
+ORDER BY name ASC
+`
+
+func (q *Queries) ListPackageAliases(ctx context.Context) ([]PackageAlias, error) {
+	rows, err := q.db.QueryContext(ctx, listPackageAliases)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PackageAlias
+	for rows.Next() {
+		var i PackageAlias
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.PackageID,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -75,7 +75,7 @@ func (q *Queries) GetPackageIDsByURLs(ctx context.Context, urls []string) ([]int
 }
 
 const listPackagesByGroup = `-- name: ListPackagesByGroup :many
-SELECT p.id, p.name, p.url, p.version, p.freq, p.created_at, p.updated_at, p.last_used, p.is_deleted
+SELECT p.id, p.name, p.url, p.version, p.freq, p.created_at, p.updated_at, p.last_used, p.is_deleted, p.module, p.import_name, p.kind, p.replace_with, p.replace_always
 FROM packages p
 JOIN group_packages gp ON gp.package_id = p.id
 JOIN groups g ON g.id = gp.group_id
@@ -102,6 +102,11 @@ func (q *Queries) ListPackagesByGroup(ctx context.Context, name string) ([]Packa
 			&i.UpdatedAt,
 			&i.LastUsed,
 			&i.IsDeleted,
+			&i.Module,
+			&i.ImportName,
+			&i.Kind,
+			&i.ReplaceWith,
+			&i.ReplaceAlways,
 		); err != nil {
 			return nil, err
 		}
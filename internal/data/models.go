@@ -0,0 +1,71 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+
+package data
+
+import (
+	"database/sql"
+)
+
+type Group struct {
+	ID        int64
+	Name      string
+	IsDeleted sql.NullInt64
+	CreatedAt sql.NullTime
+	UpdatedAt sql.NullTime
+}
+
+type GroupPackage struct {
+	GroupID   int64
+	PackageID int64
+}
+
+type Package struct {
+	ID            int64
+	Name          string
+	Url           string
+	Version       sql.NullString
+	Freq          sql.NullInt64
+	CreatedAt     sql.NullTime
+	UpdatedAt     sql.NullTime
+	LastUsed      sql.NullTime
+	IsDeleted     sql.NullInt64
+	Module        string
+	ImportName    string
+	Kind          string
+	ReplaceWith   string
+	ReplaceAlways bool
+}
+
+type PackageAlias struct {
+	ID        int64
+	Name      string
+	PackageID int64
+	CreatedAt sql.NullTime
+}
+
+type PackageInfo struct {
+	PackageID   int64
+	Version     string
+	GoVersion   string
+	Deps        int64
+	Synopsis    string
+	LicenseFile string
+	EnrichedAt  sql.NullTime
+	License     string
+}
+
+type PackageName struct {
+	PackageID int64
+	Name      string
+}
+
+type Snippet struct {
+	ID        int64
+	PackageID int64
+	Name      string
+	Body      string
+	CreatedAt sql.NullTime
+	UpdatedAt sql.NullTime
+}
@@ -0,0 +1,119 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: package_info.sql
+
+package data
+
+import (
+	"context"
+)
+
+const getPackageInfo = `-- name: GetPackageInfo :one
+SELECT package_id, version, go_version, deps, synopsis, license_file, enriched_at, license
+FROM package_info
+WHERE package_id = ?
+`
+
+func (q *Queries) GetPackageInfo(ctx context.Context, packageID int64) (PackageInfo, error) {
+	row := q.db.QueryRowContext(ctx, getPackageInfo, packageID)
+	var i PackageInfo
+	err := row.Scan(
+		&i.PackageID,
+		&i.Version,
+		&i.GoVersion,
+		&i.Deps,
+		&i.Synopsis,
+		&i.LicenseFile,
+		&i.EnrichedAt,
+		&i.License,
+	)
+	return i, err
+}
+
+const listPackageInfo = `-- name: ListPackageInfo :many
+SELECT i.package_id, i.version, i.go_version, i.deps, i.synopsis, i.license_file, i.enriched_at, i.license
+FROM package_info i
+JOIN packages p ON p.id = i.package_id
+WHERE p.is_deleted = false
+`
+
+func (q *Queries) ListPackageInfo(ctx context.Context) ([]PackageInfo, error) {
+	rows, err := q.db.QueryContext(ctx, listPackageInfo)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PackageInfo
+	for rows.Next() {
+		var i PackageInfo
+		if err := rows.Scan(
+			&i.PackageID,
+			&i.Version,
+			&i.GoVersion,
+			&i.Deps,
+			&i.Synopsis,
+			&i.LicenseFile,
+			&i.EnrichedAt,
+			&i.License,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertPackageInfo = `-- name: UpsertPackageInfo :one
+INSERT INTO package_info (package_id, version, go_version, deps, synopsis, license_file, license)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (package_id) DO UPDATE
+SET version = excluded.version,
+    go_version = excluded.go_version,
+    deps = excluded.deps,
+    synopsis = excluded.synopsis,
+    license_file = excluded.license_file,
+    license = excluded.license,
+    enriched_at = CURRENT_TIMESTAMP
+RETURNING package_id, version, go_version, deps, synopsis, license_file, enriched_at, license
+`
+
+type UpsertPackageInfoParams struct {
+	PackageID   int64
+	Version     string
+	GoVersion   string
+	Deps        int64
+	Synopsis    string
+	LicenseFile string
+	License     string
+}
+
+func (q *Queries) UpsertPackageInfo(ctx context.Context, arg UpsertPackageInfoParams) (PackageInfo, error) {
+	row := q.db.QueryRowContext(ctx, upsertPackageInfo,
+		arg.PackageID,
+		arg.Version,
+		arg.GoVersion,
+		arg.Deps,
+		arg.Synopsis,
+		arg.LicenseFile,
+		arg.License,
+	)
+	var i PackageInfo
+	err := row.Scan(
+		&i.PackageID,
+		&i.Version,
+		&i.GoVersion,
+		&i.Deps,
+		&i.Synopsis,
+		&i.LicenseFile,
+		&i.EnrichedAt,
+		&i.License,
+	)
+	return i, err
+}
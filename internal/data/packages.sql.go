@@ -12,21 +12,31 @@ import (
 )
 
 const addPackageWithVersion = `-- name: AddPackageWithVersion :one
-INSERT INTO packages (name, url, version) 
-VALUES (?, ?, ?)
+INSERT INTO packages (name, url, module, import_name, version, kind) 
+VALUES (?, ?, ?, ?, ?, ?)
 ON CONFLICT (name) DO UPDATE 
-SET is_deleted = false, url = excluded.url, version = excluded.version
-RETURNING id, name, url, version, freq, created_at, updated_at, last_used, is_deleted
+SET is_deleted = false, url = excluded.url, module = excluded.module, import_name = excluded.import_name, version = excluded.version, kind = excluded.kind
+RETURNING id, name, url, version, freq, created_at, updated_at, last_used, is_deleted, module, import_name, kind, replace_with, replace_always
 `
 
 type AddPackageWithVersionParams struct {
-	Name    string
-	Url     string
-	Version sql.NullString
+	Name       string
+	Url        string
+	Module     string
+	ImportName string
+	Version    sql.NullString
+	Kind       string
 }
 
 func (q *Queries) AddPackageWithVersion(ctx context.Context, arg AddPackageWithVersionParams) (Package, error) {
-	row := q.db.QueryRowContext(ctx, addPackageWithVersion, arg.Name, arg.Url, arg.Version)
+	row := q.db.QueryRowContext(ctx, addPackageWithVersion,
+		arg.Name,
+		arg.Url,
+		arg.Module,
+		arg.ImportName,
+		arg.Version,
+		arg.Kind,
+	)
 	var i Package
 	err := row.Scan(
 		&i.ID,
@@ -38,6 +48,11 @@ func (q *Queries) AddPackageWithVersion(ctx context.Context, arg AddPackageWithV
 		&i.UpdatedAt,
 		&i.LastUsed,
 		&i.IsDeleted,
+		&i.Module,
+		&i.ImportName,
+		&i.Kind,
+		&i.ReplaceWith,
+		&i.ReplaceAlways,
 	)
 	return i, err
 }
@@ -84,7 +99,7 @@ func (q *Queries) GetIDByName(ctx context.Context, name string) (int64, error) {
 }
 
 const getPackageByID = `-- name: GetPackageByID :one
-SELECT id, name, url, version, freq, created_at, updated_at, last_used, is_deleted FROM packages WHERE id = ? and is_deleted = false
+SELECT id, name, url, version, freq, created_at, updated_at, last_used, is_deleted, module, import_name, kind, replace_with, replace_always FROM packages WHERE id = ? and is_deleted = false
 `
 
 func (q *Queries) GetPackageByID(ctx context.Context, id int64) (Package, error) {
@@ -100,12 +115,17 @@ func (q *Queries) GetPackageByID(ctx context.Context, id int64) (Package, error)
 		&i.UpdatedAt,
 		&i.LastUsed,
 		&i.IsDeleted,
+		&i.Module,
+		&i.ImportName,
+		&i.Kind,
+		&i.ReplaceWith,
+		&i.ReplaceAlways,
 	)
 	return i, err
 }
 
 const getPackageByName = `-- name: GetPackageByName :one
-SELECT id, name, url, version, freq, created_at, updated_at, last_used, is_deleted FROM packages WHERE name =? and is_deleted = false
+SELECT id, name, url, version, freq, created_at, updated_at, last_used, is_deleted, module, import_name, kind, replace_with, replace_always FROM packages WHERE name =? and is_deleted = false
 `
 
 func (q *Queries) GetPackageByName(ctx context.Context, name string) (Package, error) {
@@ -121,6 +141,11 @@ func (q *Queries) GetPackageByName(ctx context.Context, name string) (Package, e
 		&i.UpdatedAt,
 		&i.LastUsed,
 		&i.IsDeleted,
+		&i.Module,
+		&i.ImportName,
+		&i.Kind,
+		&i.ReplaceWith,
+		&i.ReplaceAlways,
 	)
 	return i, err
 }
@@ -139,18 +164,13 @@ func (q *Queries) GetPackageIDByURL(ctx context.Context, url string) (int64, err
 }
 
 const getURLsByNames = `-- name: GetURLsByNames :many
-SELECT name, url, version 
-FROM packages 
-WHERE name IN (/*SLICE:names*/?)
+SELECT DISTINCT packages.id, packages.name, packages.url, packages.version, packages.freq, packages.created_at, packages.updated_at, packages.last_used, packages.is_deleted, packages.module, packages.import_name, packages.kind, packages.replace_with, packages.replace_always
+FROM packages
+JOIN package_names ON package_names.package_id = packages.id
+WHERE package_names.name IN (/*SLICE:names*/?)
 `
 
-type GetURLsByNamesRow struct {
-	Name    string
-	Url     string
-	Version sql.NullString
-}
-
-func (q *Queries) GetURLsByNames(ctx context.Context, names []string) ([]GetURLsByNamesRow, error) {
+func (q *Queries) GetURLsByNames(ctx context.Context, names []string) ([]Package, error) {
 	query := getURLsByNames
 	var queryParams []interface{}
 	if len(names) > 0 {
@@ -166,10 +186,25 @@ func (q *Queries) GetURLsByNames(ctx context.Context, names []string) ([]GetURLs
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetURLsByNamesRow
+	var items []Package
 	for rows.Next() {
-		var i GetURLsByNamesRow
-		if err := rows.Scan(&i.Name, &i.Url, &i.Version); err != nil {
+		var i Package
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Url,
+			&i.Version,
+			&i.Freq,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.LastUsed,
+			&i.IsDeleted,
+			&i.Module,
+			&i.ImportName,
+			&i.Kind,
+			&i.ReplaceWith,
+			&i.ReplaceAlways,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -184,7 +219,7 @@ func (q *Queries) GetURLsByNames(ctx context.Context, names []string) ([]GetURLs
 }
 
 const listPackagesByFrequency = `-- name: ListPackagesByFrequency :many
-SELECT id, name, url, version, freq, created_at, updated_at, last_used, is_deleted FROM packages
+SELECT id, name, url, version, freq, created_at, updated_at, last_used, is_deleted, module, import_name, kind, replace_with, replace_always FROM packages
 WHERE is_deleted = false
 ORDER BY freq DESC
 LIMIT ?
@@ -209,6 +244,11 @@ func (q *Queries) ListPackagesByFrequency(ctx context.Context, limit int64) ([]P
 			&i.UpdatedAt,
 			&i.LastUsed,
 			&i.IsDeleted,
+			&i.Module,
+			&i.ImportName,
+			&i.Kind,
+			&i.ReplaceWith,
+			&i.ReplaceAlways,
 		); err != nil {
 			return nil, err
 		}
@@ -224,7 +264,7 @@ func (q *Queries) ListPackagesByFrequency(ctx context.Context, limit int64) ([]P
 }
 
 const listPackagesByLastUsed = `-- name: ListPackagesByLastUsed :many
-SELECT id, name, url, version, freq, created_at, updated_at, last_used, is_deleted FROM packages
+SELECT id, name, url, version, freq, created_at, updated_at, last_used, is_deleted, module, import_name, kind, replace_with, replace_always FROM packages
 WHERE is_deleted = false
 ORDER BY last_used DESC
 LIMIT ?
@@ -249,6 +289,11 @@ func (q *Queries) ListPackagesByLastUsed(ctx context.Context, limit int64) ([]Pa
 			&i.UpdatedAt,
 			&i.LastUsed,
 			&i.IsDeleted,
+			&i.Module,
+			&i.ImportName,
+			&i.Kind,
+			&i.ReplaceWith,
+			&i.ReplaceAlways,
 		); err != nil {
 			return nil, err
 		}
@@ -305,25 +350,66 @@ func (q *Queries) MarkDeleteFalse(ctx context.Context, names []string) error {
 	return err
 }
 
+const setPackageReplace = `-- name: SetPackageReplace :one
+UPDATE packages
+SET replace_with = ?, replace_always = ?, updated_at = CURRENT_TIMESTAMP
+WHERE name = ? AND is_deleted = false
+RETURNING id, name, url, version, freq, created_at, updated_at, last_used, is_deleted, module, import_name, kind, replace_with, replace_always
+`
+
+type SetPackageReplaceParams struct {
+	ReplaceWith   string
+	ReplaceAlways bool
+	Name          string
+}
+
+func (q *Queries) SetPackageReplace(ctx context.Context, arg SetPackageReplaceParams) (Package, error) {
+	row := q.db.QueryRowContext(ctx, setPackageReplace, arg.ReplaceWith, arg.ReplaceAlways, arg.Name)
+	var i Package
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Url,
+		&i.Version,
+		&i.Freq,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.LastUsed,
+		&i.IsDeleted,
+		&i.Module,
+		&i.ImportName,
+		&i.Kind,
+		&i.ReplaceWith,
+		&i.ReplaceAlways,
+	)
+	return i, err
+}
+
 const updatePackage = `-- name: UpdatePackage :one
 UPDATE packages
-SET name = ?, url = ?, version = ?
+SET name = ?, url = ?, module = ?, import_name = ?, version = ?, kind = ?
 WHERE id = ?
-RETURNING id, name, url, version, freq, created_at, updated_at, last_used, is_deleted
+RETURNING id, name, url, version, freq, created_at, updated_at, last_used, is_deleted, module, import_name, kind, replace_with, replace_always
 `
 
 type UpdatePackageParams struct {
-	Name    string
-	Url     string
-	Version sql.NullString
-	ID      int64
+	Name       string
+	Url        string
+	Module     string
+	ImportName string
+	Version    sql.NullString
+	Kind       string
+	ID         int64
 }
 
 func (q *Queries) UpdatePackage(ctx context.Context, arg UpdatePackageParams) (Package, error) {
 	row := q.db.QueryRowContext(ctx, updatePackage,
 		arg.Name,
 		arg.Url,
+		arg.Module,
+		arg.ImportName,
 		arg.Version,
+		arg.Kind,
 		arg.ID,
 	)
 	var i Package
@@ -337,25 +423,40 @@ func (q *Queries) UpdatePackage(ctx context.Context, arg UpdatePackageParams) (P
 		&i.UpdatedAt,
 		&i.LastUsed,
 		&i.IsDeleted,
+		&i.Module,
+		&i.ImportName,
+		&i.Kind,
+		&i.ReplaceWith,
+		&i.ReplaceAlways,
 	)
 	return i, err
 }
 
 const updatePackageByName = `-- name: UpdatePackageByName :one
 UPDATE packages
-SET url = ?, version = ?
+SET url = ?, module = ?, import_name = ?, version = ?, kind = ?
 WHERE name = ?
-RETURNING id, name, url, version, freq, created_at, updated_at, last_used, is_deleted
+RETURNING id, name, url, version, freq, created_at, updated_at, last_used, is_deleted, module, import_name, kind, replace_with, replace_always
 `
 
 type UpdatePackageByNameParams struct {
-	Url     string
-	Version sql.NullString
-	Name    string
+	Url        string
+	Module     string
+	ImportName string
+	Version    sql.NullString
+	Kind       string
+	Name       string
 }
 
 func (q *Queries) UpdatePackageByName(ctx context.Context, arg UpdatePackageByNameParams) (Package, error) {
-	row := q.db.QueryRowContext(ctx, updatePackageByName, arg.Url, arg.Version, arg.Name)
+	row := q.db.QueryRowContext(ctx, updatePackageByName,
+		arg.Url,
+		arg.Module,
+		arg.ImportName,
+		arg.Version,
+		arg.Kind,
+		arg.Name,
+	)
 	var i Package
 	err := row.Scan(
 		&i.ID,
@@ -367,6 +468,11 @@ func (q *Queries) UpdatePackageByName(ctx context.Context, arg UpdatePackageByNa
 		&i.UpdatedAt,
 		&i.LastUsed,
 		&i.IsDeleted,
+		&i.Module,
+		&i.ImportName,
+		&i.Kind,
+		&i.ReplaceWith,
+		&i.ReplaceAlways,
 	)
 	return i, err
 }
@@ -0,0 +1,51 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+
+package data
+
+import (
+	"context"
+)
+
+type Querier interface {
+	AddPackageAlias(ctx context.Context, arg AddPackageAliasParams) (PackageAlias, error)
+	AddPackageWithVersion(ctx context.Context, arg AddPackageWithVersionParams) (Package, error)
+	AssignPackageToGroup(ctx context.Context, arg AssignPackageToGroupParams) error
+	CleanDatabase(ctx context.Context) error
+	CreateGroup(ctx context.Context, name string) (Group, error)
+	DeleteGroup(ctx context.Context, name string) error
+	DeletePackageAlias(ctx context.Context, name string) (int64, error)
+	DeletePackagesByName(ctx context.Context, names []string) error
+	DeleteSnippet(ctx context.Context, arg DeleteSnippetParams) (int64, error)
+	GetGroupIDByName(ctx context.Context, name string) (int64, error)
+	GetIDByName(ctx context.Context, name string) (int64, error)
+	GetPackageAlias(ctx context.Context, name string) (PackageAlias, error)
+	GetPackageAliasesByNames(ctx context.Context, names []string) ([]PackageAlias, error)
+	GetPackageByID(ctx context.Context, id int64) (Package, error)
+	GetPackageByName(ctx context.Context, name string) (Package, error)
+	GetPackageIDByURL(ctx context.Context, url string) (int64, error)
+	GetPackageIDsByURLs(ctx context.Context, urls []string) ([]int64, error)
+	GetPackageInfo(ctx context.Context, packageID int64) (PackageInfo, error)
+	GetSnippet(ctx context.Context, arg GetSnippetParams) (Snippet, error)
+	GetURLsByNames(ctx context.Context, names []string) ([]Package, error)
+	ListAliasesByPackage(ctx context.Context, packageID int64) ([]PackageAlias, error)
+	ListGroups(ctx context.Context) ([]Group, error)
+	ListPackageAliases(ctx context.Context) ([]PackageAlias, error)
+	ListPackageInfo(ctx context.Context) ([]PackageInfo, error)
+	ListPackagesByFrequency(ctx context.Context, limit int64) ([]Package, error)
+	ListPackagesByGroup(ctx context.Context, name string) ([]Package, error)
+	ListPackagesByLastUsed(ctx context.Context, limit int64) ([]Package, error)
+	ListSnippetsByPackage(ctx context.Context, packageID int64) ([]Snippet, error)
+	MarkDeleteByName(ctx context.Context, names []string) error
+	MarkDeleteFalse(ctx context.Context, names []string) error
+	RemovePackagesFromGroup(ctx context.Context, arg RemovePackagesFromGroupParams) error
+	SetPackageReplace(ctx context.Context, arg SetPackageReplaceParams) (Package, error)
+	UpdatePackage(ctx context.Context, arg UpdatePackageParams) (Package, error)
+	UpdatePackageByName(ctx context.Context, arg UpdatePackageByNameParams) (Package, error)
+	UpdatePackageUsage(ctx context.Context, url string) error
+	UpsertPackageInfo(ctx context.Context, arg UpsertPackageInfoParams) (PackageInfo, error)
+	UpsertSnippet(ctx context.Context, arg UpsertSnippetParams) (Snippet, error)
+}
+
+var _ Querier = (*Queries)(nil)
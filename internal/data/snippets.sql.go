@@ -0,0 +1,118 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: snippets.sql
+
+package data
+
+import (
+	"context"
+)
+
+const deleteSnippet = `-- name: DeleteSnippet :execrows
+DELETE FROM snippets
+WHERE package_id = ? AND name = ?
+`
+
+type DeleteSnippetParams struct {
+	PackageID int64
+	Name      string
+}
+
+func (q *Queries) DeleteSnippet(ctx context.Context, arg DeleteSnippetParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteSnippet, arg.PackageID, arg.Name)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getSnippet = `-- name: GetSnippet :one
+SELECT id, package_id, name, body, created_at, updated_at
+FROM snippets
+WHERE package_id = ? AND name = ?
+`
+
+type GetSnippetParams struct {
+	PackageID int64
+	Name      string
+}
+
+func (q *Queries) GetSnippet(ctx context.Context, arg GetSnippetParams) (Snippet, error) {
+	row := q.db.QueryRowContext(ctx, getSnippet, arg.PackageID, arg.Name)
+	var i Snippet
+	err := row.Scan(
+		&i.ID,
+		&i.PackageID,
+		&i.Name,
+		&i.Body,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listSnippetsByPackage = `-- name: ListSnippetsByPackage :many
+SELECT id, package_id, name, body, created_at, updated_at
+FROM snippets
+WHERE package_id = ?
+ORDER BY name ASC
+`
+
+func (q *Queries) ListSnippetsByPackage(ctx context.Context, packageID int64) ([]Snippet, error) {
+	rows, err := q.db.QueryContext(ctx, listSnippetsByPackage, packageID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Snippet
+	for rows.Next() {
+		var i Snippet
+		if err := rows.Scan(
+			&i.ID,
+			&i.PackageID,
+			&i.Name,
+			&i.Body,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertSnippet = `-- name: UpsertSnippet :one
+INSERT INTO snippets (package_id, name, body)
+VALUES (?, ?, ?)
+ON CONFLICT (package_id, name) DO UPDATE
+SET body = excluded.body, updated_at = CURRENT_TIMESTAMP
+RETURNING id, package_id, name, body, created_at, updated_at
+`
+
+type UpsertSnippetParams struct {
+	PackageID int64
+	Name      string
+	Body      string
+}
+
+func (q *Queries) UpsertSnippet(ctx context.Context, arg UpsertSnippetParams) (Snippet, error) {
+	row := q.db.QueryRowContext(ctx, upsertSnippet, arg.PackageID, arg.Name, arg.Body)
+	var i Snippet
+	err := row.Scan(
+		&i.ID,
+		&i.PackageID,
+		&i.Name,
+		&i.Body,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,128 @@
+package gotool
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Call is a go command recorded by Fake.
+type Call struct {
+	// Cmd is the subcommand: "get", "install", "mod init", "mod edit",
+	// "list -m" or "env".
+	Cmd  string
+	Args []string
+}
+
+func (c Call) String() string {
+	return strings.TrimSpace("go " + c.Cmd + " " + strings.Join(c.Args, " "))
+}
+
+// Fake is a Runner that records calls instead of running go. Its behavior
+// is scripted through its fields, which may be set before use:
+//
+//	fake := &gotool.Fake{
+//		Fail: map[string]error{"example.com/broken": errors.New("no matching versions")},
+//	}
+//	service.Toolchain = fake
+//
+// Like the go command, a call fails as a whole if any of its arguments
+// fails: 'go get a b' does not install a when b cannot be resolved.
+type Fake struct {
+	// Fail maps an argument, either a path or a full path@version query,
+	// to the error a call including it returns.
+	Fail map[string]error
+
+	// Modules are returned by ListModules. Arguments filter them by path;
+	// "all" or no arguments return every module.
+	Modules []Module
+
+	// Environ holds the values returned by Env. Unset variables are
+	// empty, as with 'go env'.
+	Environ map[string]string
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ Runner = (*Fake)(nil)
+
+// Calls returns the calls made so far, in order.
+func (f *Fake) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Call(nil), f.calls...)
+}
+
+func (f *Fake) record(cmd string, args []string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	call := Call{Cmd: cmd, Args: append([]string(nil), args...)}
+	f.calls = append(f.calls, call)
+
+	for _, arg := range args {
+		path, _ := Version(arg)
+		for _, key := range []string{arg, path} {
+			if err, ok := f.Fail[key]; ok {
+				return &Error{Args: append(strings.Fields(cmd), args...), Output: fmt.Sprintf("%s: %v", arg, err), Err: err}
+			}
+		}
+	}
+	return nil
+}
+
+func (f *Fake) Get(ctx context.Context, args ...string) error {
+	return f.record("get", args)
+}
+
+func (f *Fake) Install(ctx context.Context, args ...string) error {
+	return f.record("install", args)
+}
+
+func (f *Fake) ModInit(ctx context.Context, path string) error {
+	return f.record("mod init", []string{path})
+}
+
+func (f *Fake) ModEdit(ctx context.Context, args ...string) error {
+	return f.record("mod edit", args)
+}
+
+func (f *Fake) ListModules(ctx context.Context, args ...string) ([]Module, error) {
+	if err := f.record("list -m", args); err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, arg := range args {
+		if arg != "all" && !strings.HasPrefix(arg, "-") {
+			paths = append(paths, arg)
+		}
+	}
+	if len(paths) == 0 {
+		return append([]Module(nil), f.Modules...), nil
+	}
+
+	var mods []Module
+	for _, m := range f.Modules {
+		for _, p := range paths {
+			if path, _ := Version(p); path == m.Path {
+				mods = append(mods, m)
+			}
+		}
+	}
+	return mods, nil
+}
+
+func (f *Fake) Env(ctx context.Context, names ...string) ([]string, error) {
+	if err := f.record("env", names); err != nil {
+		return nil, err
+	}
+
+	values := make([]string, len(names))
+	for i, name := range names {
+		values[i] = f.Environ[name]
+	}
+	return values, nil
+}
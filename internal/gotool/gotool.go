@@ -0,0 +1,173 @@
+// Package gotool runs the go command on behalf of gopk.
+//
+// Every toolchain call goes through a Runner so that installs can be
+// exercised without a network or a go binary, using Fake.
+package gotool
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// Runner runs go subcommands. Implementations must be safe for sequential
+// use; they are not expected to be used concurrently.
+type Runner interface {
+	// Get runs 'go get' with module queries such as "example.com/m" or
+	// "example.com/m@v1.2.3" in the current module.
+	Get(ctx context.Context, args ...string) error
+
+	// Install runs 'go install' with package queries such as
+	// "example.com/m/cmd/tool@latest".
+	Install(ctx context.Context, args ...string) error
+
+	// ModInit runs 'go mod init' for a new module with the given path.
+	ModInit(ctx context.Context, path string) error
+
+	// ModEdit runs 'go mod edit' with flags such as "-replace=old=new".
+	ModEdit(ctx context.Context, args ...string) error
+
+	// ListModules runs 'go list -m -json' with args and decodes the result.
+	ListModules(ctx context.Context, args ...string) ([]Module, error)
+
+	// Env runs 'go env' for the named variables and returns their values
+	// in order.
+	Env(ctx context.Context, names ...string) ([]string, error)
+}
+
+// Module is a module as reported by 'go list -m -json'.
+type Module struct {
+	Path     string
+	Version  string
+	Main     bool
+	Indirect bool
+	Dir      string
+	GoMod    string
+	Replace  *Module
+}
+
+// Error is returned when the go command exits with an error. Output holds
+// what it printed.
+type Error struct {
+	Args   []string
+	Output string
+	Err    error
+}
+
+// maxOutput bounds the output kept in Error messages; the go command can
+// print whole dependency graphs on failure.
+const maxOutput = 200
+
+func (e *Error) Error() string {
+	output := e.Output
+	if output == "" {
+		output = e.Err.Error()
+	}
+	if len(output) > maxOutput {
+		output = output[:maxOutput-3] + "..."
+	}
+	return output
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Exec runs a go binary.
+type Exec struct {
+	// Go is the go binary to run, such as "go1.22.0" or an absolute path.
+	// It defaults to "go".
+	Go string
+
+	// Dir is the directory to run in. It defaults to the current directory.
+	Dir string
+}
+
+var _ Runner = Exec{}
+
+func (e Exec) run(ctx context.Context, stdout io.Writer, args ...string) error {
+	bin := e.Go
+	if bin == "" {
+		bin = "go"
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, bin, args...)
+	cmd.Dir = e.Dir
+	cmd.Stdout = stdout
+	cmd.Stderr = &stderr
+	if stdout == nil {
+		cmd.Stdout = &stderr
+	}
+
+	if err := cmd.Run(); err != nil {
+		return &Error{Args: args, Output: strings.TrimSpace(stderr.String()), Err: err}
+	}
+	return nil
+}
+
+func (e Exec) Get(ctx context.Context, args ...string) error {
+	return e.run(ctx, nil, append([]string{"get"}, args...)...)
+}
+
+func (e Exec) Install(ctx context.Context, args ...string) error {
+	return e.run(ctx, nil, append([]string{"install"}, args...)...)
+}
+
+func (e Exec) ModInit(ctx context.Context, path string) error {
+	return e.run(ctx, nil, "mod", "init", path)
+}
+
+func (e Exec) ModEdit(ctx context.Context, args ...string) error {
+	return e.run(ctx, nil, append([]string{"mod", "edit"}, args...)...)
+}
+
+func (e Exec) ListModules(ctx context.Context, args ...string) ([]Module, error) {
+	var out bytes.Buffer
+	if err := e.run(ctx, &out, append([]string{"list", "-m", "-json"}, args...)...); err != nil {
+		return nil, err
+	}
+	return decodeModules(&out)
+}
+
+func (e Exec) Env(ctx context.Context, names ...string) ([]string, error) {
+	var out bytes.Buffer
+	if err := e.run(ctx, &out, append([]string{"env"}, names...)...); err != nil {
+		return nil, err
+	}
+	values := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(values) != len(names) {
+		return nil, fmt.Errorf("go env: got %d values for %d variables", len(values), len(names))
+	}
+	return values, nil
+}
+
+// decodeModules reads the stream of JSON objects printed by
+// 'go list -m -json'.
+func decodeModules(r io.Reader) ([]Module, error) {
+	var mods []Module
+	dec := json.NewDecoder(r)
+	for {
+		var m Module
+		err := dec.Decode(&m)
+		if errors.Is(err, io.EOF) {
+			return mods, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		mods = append(mods, m)
+	}
+}
+
+// Version splits a module or package query into its path and version.
+// The version is empty when the query has none.
+func Version(query string) (path, version string) {
+	path, version, _ = strings.Cut(query, "@")
+	return path, version
+}
@@ -0,0 +1,139 @@
+// Package license classifies license files by their text.
+package license
+
+import (
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const Unknown = "unknown"
+
+var (
+	space   = regexp.MustCompile(`\s+`)
+	version = regexp.MustCompile(`version (\d+(?:\.\d+)?)`)
+)
+
+// headLines is the number of non-blank lines searched for a license title.
+// Full license texts start with their title and version, while their body
+// may mention other licenses: the GPL refers to the Affero GPL, the MPL to
+// the GPL family.
+const headLines = 6
+
+// titles are the licenses recognized by their title and version, which
+// must appear in the head of the text.
+var titles = []struct {
+	title    string
+	versions map[string]string // version to SPDX identifier; "" if unversioned
+}{
+	{"gnu affero general public license", map[string]string{"3": "AGPL-3.0"}},
+	{"gnu lesser general public license", map[string]string{"3": "LGPL-3.0", "2.1": "LGPL-2.1"}},
+	{"gnu library general public license", map[string]string{"2": "LGPL-2.0"}},
+	{"gnu general public license", map[string]string{"3": "GPL-3.0", "2": "GPL-2.0", "1": "GPL-1.0"}},
+	{"mozilla public license", map[string]string{"2.0": "MPL-2.0", "1.1": "MPL-1.1"}},
+	{"apache license", map[string]string{"2.0": "Apache-2.0"}},
+	{"cc0 1.0 universal", map[string]string{"": "CC0-1.0"}},
+	{"this is free and unencumbered software released into the public domain", map[string]string{"": "Unlicense"}},
+}
+
+// rules are the licenses without a title, recognized by phrases anywhere
+// in the text. They are checked in order, so BSD-3-Clause comes before
+// BSD-2-Clause, whose text it contains.
+var rules = []struct {
+	id      string
+	phrases []string
+}{
+	{"BSD-3-Clause", []string{"redistribution and use in source and binary forms", "neither the name"}},
+	{"BSD-3-Clause", []string{"redistribution and use in source and binary forms", "names of its contributors may not be used"}},
+	{"BSD-2-Clause", []string{"redistribution and use in source and binary forms"}},
+	{"ISC", []string{"permission to use, copy, modify, and/or distribute this software for any purpose"}},
+	{"MIT", []string{"permission is hereby granted, free of charge", "the above copyright notice and this permission notice shall be included"}},
+}
+
+// Classify returns the SPDX identifier of the license in text, or Unknown
+// if it is not recognized. A title in the head of the text wins over the
+// phrases of untitled licenses.
+func Classify(text []byte) string {
+	if id, ok := classifyTitle(head(text)); ok {
+		return id
+	}
+	norm := normalize(string(text))
+	for _, r := range rules {
+		if containsAll(norm, r.phrases) {
+			return r.id
+		}
+	}
+	return Unknown
+}
+
+// classifyTitle returns the license whose title comes first in head,
+// with the first version number that follows it.
+func classifyTitle(head string) (string, bool) {
+	best, id := -1, ""
+	for _, t := range titles {
+		i := strings.Index(head, t.title)
+		if i < 0 || (best >= 0 && i >= best) {
+			continue
+		}
+		v := ""
+		if m := version.FindStringSubmatch(head[i+len(t.title):]); m != nil {
+			v = m[1]
+		}
+		if match, ok := t.versions[v]; ok {
+			best, id = i, match
+		} else if match, ok := t.versions[""]; ok {
+			best, id = i, match
+		}
+	}
+	return id, best >= 0
+}
+
+// head returns the first headLines non-blank lines of text, normalized.
+func head(text []byte) string {
+	var lines []string
+	for line := range strings.Lines(string(text)) {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lines = append(lines, line)
+		if len(lines) == headLines {
+			break
+		}
+	}
+	return normalize(strings.Join(lines, " "))
+}
+
+func normalize(s string) string {
+	return space.ReplaceAllString(strings.ToLower(s), " ")
+}
+
+// ClassifyFile reads and classifies the license file name. A missing or
+// unreadable file is reported as Unknown.
+func ClassifyFile(name string) string {
+	text, err := os.ReadFile(name)
+	if err != nil {
+		return Unknown
+	}
+	return Classify(text)
+}
+
+// Matches reports whether id matches any of the patterns, which may use
+// path.Match wildcards such as "BSD-*". Matching is case-insensitive.
+func Matches(id string, patterns []string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(strings.ToLower(p), strings.ToLower(id)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAll(s string, phrases []string) bool {
+	for _, p := range phrases {
+		if !strings.Contains(s, p) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,59 @@
+package license
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestClassifyFiles(t *testing.T) {
+	tests := []struct {
+		file, want string
+	}{
+		{"Apache-2.0", "Apache-2.0"},
+		{"Artistic", Unknown},
+		{"BSD", "BSD-3-Clause"},
+		{"BSD-2-Clause", "BSD-2-Clause"},
+		{"BSD-3-Clause", "BSD-3-Clause"},
+		{"CC0-1.0", "CC0-1.0"},
+		{"GFDL-1.3", Unknown},
+		{"GPL-1", "GPL-1.0"},
+		{"GPL-2", "GPL-2.0"},
+		{"GPL-3", "GPL-3.0"},
+		{"ISC", "ISC"},
+		{"LGPL-2", "LGPL-2.0"},
+		{"LGPL-2.1", "LGPL-2.1"},
+		{"LGPL-3", "LGPL-3.0"},
+		{"MIT", "MIT"},
+		{"MPL-1.1", "MPL-1.1"},
+		{"MPL-2.0", "MPL-2.0"},
+	}
+	for _, tt := range tests {
+		text, err := os.ReadFile(filepath.Join("testdata", tt.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := Classify(text); got != tt.want {
+			t.Errorf("Classify(%s) = %s, want %s", tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		text, want string
+	}{
+		{"                    GNU AFFERO GENERAL PUBLIC LICENSE\n                       Version 3, 19 November 2007\n\n" +
+			"The GNU Affero General Public License is a free, copyleft license for\nsoftware...", "AGPL-3.0"},
+		{"This is free and unencumbered software released into the public domain.\n\n" +
+			"Anyone is free to copy, modify, publish, use, compile, sell, or\ndistribute this software...", "Unlicense"},
+		{"Licensed under the GNU General Public License, version 2 or later.", "GPL-2.0"},
+		{"All rights reserved.", Unknown},
+		{"", Unknown},
+	}
+	for _, tt := range tests {
+		if got := Classify([]byte(tt.text)); got != tt.want {
+			t.Errorf("Classify(%.40q) = %s, want %s", tt.text, got, tt.want)
+		}
+	}
+}
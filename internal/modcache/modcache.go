@@ -0,0 +1,148 @@
+// Package modcache reads module sources from the local Go module cache
+// without touching the network.
+package modcache
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lewvy/gopk/internal/license"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+var ErrNotCached = errors.New("module not found in module cache")
+
+// Module is a module version extracted in the module cache.
+type Module struct {
+	Path    string
+	Version string
+	Dir     string
+}
+
+// Dir returns the module cache root, following the same rules as the go
+// command: $GOMODCACHE, then the first $GOPATH entry, then ~/go.
+func Dir() (string, error) {
+	if dir := os.Getenv("GOMODCACHE"); dir != "" {
+		return dir, nil
+	}
+	if gopath := os.Getenv("GOPATH"); gopath != "" {
+		return filepath.Join(filepath.SplitList(gopath)[0], "pkg", "mod"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "go", "pkg", "mod"), nil
+}
+
+// Versions returns the cached versions of the module at path, sorted from
+// oldest to newest.
+func Versions(path string) ([]string, error) {
+	root, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	escaped, err := module.EscapePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := filepath.Glob(filepath.Join(root, escaped+"@*"))
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []string
+	for _, m := range matches {
+		_, ev, _ := strings.Cut(filepath.Base(m), "@")
+		v, err := module.UnescapeVersion(ev)
+		if err != nil || !semver.IsValid(v) {
+			continue
+		}
+		if info, err := os.Stat(m); err != nil || !info.IsDir() {
+			continue
+		}
+		versions = append(versions, v)
+	}
+	semver.Sort(versions)
+	return versions, nil
+}
+
+// Lookup finds the cached copy of the module at path. An empty version, or
+// one that is not a semantic version such as "latest", selects the newest
+// cached version.
+func Lookup(path, version string) (Module, error) {
+	root, err := Dir()
+	if err != nil {
+		return Module{}, err
+	}
+
+	if !semver.IsValid(version) {
+		versions, err := Versions(path)
+		if err != nil {
+			return Module{}, err
+		}
+		if len(versions) == 0 {
+			return Module{}, fmt.Errorf("%w: %s", ErrNotCached, path)
+		}
+		version = versions[len(versions)-1]
+	}
+
+	escaped, err := module.EscapePath(path)
+	if err != nil {
+		return Module{}, err
+	}
+	ev, err := module.EscapeVersion(version)
+	if err != nil {
+		return Module{}, err
+	}
+
+	dir := filepath.Join(root, escaped+"@"+ev)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return Module{}, fmt.Errorf("%w: %s@%s", ErrNotCached, path, version)
+	}
+
+	return Module{Path: path, Version: version, Dir: dir}, nil
+}
+
+// PackageDir returns the directory holding importPath within m.
+func (m Module) PackageDir(importPath string) string {
+	rel := strings.TrimPrefix(strings.TrimPrefix(importPath, m.Path), "/")
+	return filepath.Join(m.Dir, filepath.FromSlash(rel))
+}
+
+var licenseNames = []string{
+	"LICENSE", "LICENSE.md", "LICENSE.txt", "LICENSE-MIT", "LICENSE-APACHE",
+	"LICENCE", "LICENCE.md", "LICENCE.txt", "COPYING", "COPYING.md", "COPYING.txt",
+}
+
+// LicenseFile returns the name of the license file at the module root, or
+// an empty string if there is none.
+func (m Module) LicenseFile() string {
+	entries, err := os.ReadDir(m.Dir)
+	if err != nil {
+		return ""
+	}
+	for _, name := range licenseNames {
+		for _, e := range entries {
+			if !e.IsDir() && strings.EqualFold(e.Name(), name) {
+				return e.Name()
+			}
+		}
+	}
+	return ""
+}
+
+// License classifies the license file at the module root, returning
+// license.Unknown if there is none or it is not recognized.
+func (m Module) License() string {
+	name := m.LicenseFile()
+	if name == "" {
+		return license.Unknown
+	}
+	return license.ClassifyFile(filepath.Join(m.Dir, name))
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go/token"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/lewvy/gopk/internal/data"
+	"github.com/lewvy/gopk/internal/store"
+)
+
+var (
+	moduleVerRe         = regexp.MustCompile(`^v\d+$`)
+	ErrConstraintUnique = errors.New("package already exists")
+	ErrNotFound         = errors.New("package not found in the registry")
+)
+
+// AddParams describes a package to save in the registry. Only URL is
+// required; the alias and module root are inferred when left empty, the
+// alias with InferAlias.
+type AddParams struct {
+	URL        string
+	Module     string
+	Name       string
+	ImportName string
+	Version    string
+	Kind       string
+	Install    bool
+	Force      bool
+
+	// InstallOptions apply when Install is set.
+	InstallOptions InstallOptions
+}
+
+// Add saves a package and returns it, installing it into the current
+// module when p.Install is set.
+func Add(ctx context.Context, queries data.Querier, p AddParams) (data.Package, error) {
+	url := normalizeURL(p.URL)
+	name := p.Name
+	if name == "" {
+		proposal, err := InferAlias(ctx, queries, url)
+		if err != nil {
+			return data.Package{}, err
+		}
+		if proposal.Collided() {
+			Warn(fmt.Sprintf("%s is the alias of %s; saving %s as %s", proposal.Base, proposal.Owner.Url, url, proposal.Alias))
+		}
+		name = proposal.Alias
+	}
+	module := normalizeURL(p.Module)
+	if module == "" {
+		module = inferModule(url)
+	}
+	if url != module && !strings.HasPrefix(url, module+"/") {
+		return data.Package{}, fmt.Errorf("import path %s is not inside module %s", url, module)
+	}
+	if p.ImportName != "" && !token.IsIdentifier(p.ImportName) {
+		return data.Package{}, fmt.Errorf("invalid import name %q", p.ImportName)
+	}
+	kind, err := normalizeKind(p.Kind)
+	if err != nil {
+		return data.Package{}, err
+	}
+
+	// The alias may already be an extra alias of another package, which
+	// --force does not take over. A primary alias only moves to another
+	// import path with --force, rather than silently through the upsert.
+	if owner, taken, err := AliasOwner(ctx, queries, name); err != nil {
+		return data.Package{}, err
+	} else if taken && owner.Name != name {
+		return data.Package{}, aliasTaken(name, owner)
+	} else if taken && owner.Url != url && !p.Force {
+		return data.Package{}, existsError(ctx, queries, name, url)
+	}
+
+	addParams := data.AddPackageWithVersionParams{
+		Name:       name,
+		Url:        url,
+		Module:     module,
+		ImportName: p.ImportName,
+		Version:    sql.NullString{Valid: true, String: p.Version},
+		Kind:       kind,
+	}
+
+	pkg, err := queries.AddPackageWithVersion(ctx, addParams)
+
+	if err != nil {
+		if isUniqueConstraintErr(err) {
+			if p.Force {
+				updateParams := data.UpdatePackageByNameParams{
+					Url:        url,
+					Module:     module,
+					ImportName: p.ImportName,
+					Name:       name,
+					Version:    sql.NullString{Valid: true, String: p.Version},
+					Kind:       kind,
+				}
+				pkg, err = queries.UpdatePackageByName(ctx, updateParams)
+				if err != nil {
+					return data.Package{}, fmt.Errorf("failed to force update: %w", err)
+				}
+			} else {
+				return data.Package{}, existsError(ctx, queries, name, url)
+			}
+		} else {
+			return data.Package{}, err
+		}
+	}
+
+	if p.Install {
+		return pkg, InstallPackages(ctx, queries, []data.Package{pkg}, p.InstallOptions)
+	}
+
+	return pkg, nil
+}
+
+// existsError returns an error wrapping ErrConstraintUnique that names
+// the package already saved under name or url.
+func existsError(ctx context.Context, queries data.Querier, name, url string) error {
+	if pkg, err := queries.GetPackageByName(ctx, name); err == nil {
+		return fmt.Errorf("%w: %s is the alias of %s", ErrConstraintUnique, name, pkg.Url)
+	}
+	if id, err := queries.GetPackageIDByURL(ctx, url); err == nil {
+		if pkg, err := queries.GetPackageByID(ctx, id); err == nil {
+			return fmt.Errorf("%w: %s is saved as %s", ErrConstraintUnique, url, pkg.Name)
+		}
+	}
+	return ErrConstraintUnique
+}
+
+func isUniqueConstraintErr(err error) bool {
+	return store.IsUniqueConstraint(err)
+}
+
+func getAlias(u string) string {
+	name := path.Base(u)
+
+	for moduleVerRe.MatchString(name) {
+		u = strings.TrimSuffix(u, "/"+name)
+		name = path.Base(u)
+	}
+
+	if name == "." || name == "/" {
+		return u
+	}
+
+	return name
+}
+
+func normalizeURL(u string) string {
+	u, _ = strings.CutPrefix(u, "https://")
+	u, _ = strings.CutPrefix(u, "http://")
+	return u
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/lewvy/gopk/config"
+	"github.com/lewvy/gopk/internal/data"
+)
+
+var gopkgVersionRe = regexp.MustCompile(`\.v\d+$`)
+
+// AliasProposal is the alias inferred for an import path.
+type AliasProposal struct {
+	// Alias is the proposed alias. It is free in the registry, or already
+	// the alias of the same import path.
+	Alias string
+
+	// Base is the alias inferred from the path alone.
+	Base string
+
+	// Owner is the package Base belongs to when it is another package,
+	// in which case Alias is an owner-qualified form of Base.
+	Owner data.Package
+}
+
+// Collided reports whether Base belongs to another package.
+func (p AliasProposal) Collided() bool {
+	return p.Owner.ID != 0
+}
+
+// InferAlias proposes an alias for the package at url, following the
+// [alias] section of the config file. A saved import path keeps its alias;
+// otherwise the last path element is used, with major version elements
+// dropped and, by default, common decorations stripped:
+// github.com/mattn/go-sqlite3 is sqlite3 and gopkg.in/yaml.v3 is yaml.
+//
+// When that alias belongs to another package, owner-qualified aliases are
+// tried in turn, such as go-chi/chi for github.com/go-chi/chi/v5, then the
+// path without its host and finally url itself. With collision = "error"
+// the proposal is returned along with an error wrapping ErrAliasTaken.
+func InferAlias(ctx context.Context, q data.Querier, url string) (AliasProposal, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return AliasProposal{}, err
+	}
+	url = normalizeURL(url)
+
+	// A saved import path keeps its alias.
+	if id, err := q.GetPackageIDByURL(ctx, url); err == nil {
+		if pkg, err := q.GetPackageByID(ctx, id); err == nil {
+			return AliasProposal{Alias: pkg.Name, Base: pkg.Name}, nil
+		}
+	}
+
+	base := baseAlias(url, cfg.Alias.Conventions == "strip")
+	p := AliasProposal{Alias: base, Base: base}
+	owner, taken, err := AliasOwner(ctx, q, base)
+	if err != nil || !taken || owner.Url == url {
+		return p, err
+	}
+	p.Owner = owner
+	if cfg.Alias.Collision == "error" {
+		return p, aliasTaken(base, owner)
+	}
+
+	for _, candidate := range qualifiedAliases(url, base) {
+		other, taken, err := AliasOwner(ctx, q, candidate)
+		if err != nil {
+			return p, err
+		}
+		if !taken || other.Url == url {
+			p.Alias = candidate
+			return p, nil
+		}
+	}
+	return p, aliasTaken(base, owner)
+}
+
+// baseAlias is the alias of url ignoring the registry: its last path
+// element without major version elements and, with strip, without a "go-"
+// prefix, a ".go" or "-go" suffix or a gopkg.in ".vN" version.
+func baseAlias(url string, strip bool) string {
+	name := getAlias(url)
+	if !strip {
+		return name
+	}
+
+	if strings.HasPrefix(url, "gopkg.in/") {
+		name = gopkgVersionRe.ReplaceAllString(name, "")
+	}
+	stripped := strings.TrimPrefix(name, "go-")
+	stripped = strings.TrimSuffix(stripped, ".go")
+	stripped = strings.TrimSuffix(stripped, "-go")
+	if stripped == "" {
+		return name
+	}
+	return stripped
+}
+
+// qualifiedAliases returns the aliases to try for url when base is taken,
+// most readable first: owner/base, the path without its host, and url.
+func qualifiedAliases(url, base string) []string {
+	parts := strings.Split(url, "/")
+	for len(parts) > 1 && moduleVerRe.MatchString(parts[len(parts)-1]) {
+		parts = parts[:len(parts)-1]
+	}
+
+	var out []string
+	add := func(alias string) {
+		if alias != base && !slices.Contains(out, alias) {
+			out = append(out, alias)
+		}
+	}
+
+	if len(parts) > 2 {
+		add(parts[len(parts)-2] + "/" + base)
+	} else if len(parts) == 2 {
+		add(hostLabel(parts[0]) + "/" + base)
+	}
+	if len(parts) > 1 {
+		add(strings.Join(parts[1:], "/"))
+	}
+	add(url)
+	return out
+}
+
+// hostLabel returns the name of the organization behind host: uber for
+// go.uber.org, gopkg for gopkg.in.
+func hostLabel(host string) string {
+	labels := strings.Split(host, ".")
+	if len(labels) < 2 {
+		return host
+	}
+	return labels[len(labels)-2]
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/lewvy/gopk/internal/data"
+)
+
+var (
+	ErrAliasTaken    = errors.New("alias already in use")
+	ErrAliasNotFound = errors.New("alias not found")
+)
+
+// Alias is an extra alias of a saved package. Every package is saved
+// under its primary alias, data.Package.Name, and can be given any
+// number of extra aliases that resolve to it.
+type Alias struct {
+	Name    string
+	Package data.Package
+}
+
+// Aliases returns the extra aliases of the live packages, sorted by name.
+func Aliases(ctx context.Context, q data.Querier) ([]Alias, error) {
+	rows, err := q.ListPackageAliases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pkgs, err := q.ListPackagesByLastUsed(ctx, -1)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]data.Package, len(pkgs))
+	for _, pkg := range pkgs {
+		byID[pkg.ID] = pkg
+	}
+
+	var out []Alias
+	for _, row := range rows {
+		if pkg, ok := byID[row.PackageID]; ok {
+			out = append(out, Alias{Name: row.Name, Package: pkg})
+		}
+	}
+	return out, nil
+}
+
+// AliasesByPackage returns the extra aliases of the live packages, by
+// package ID.
+func AliasesByPackage(ctx context.Context, q data.Querier) (map[int64][]string, error) {
+	aliases, err := Aliases(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[int64][]string)
+	for _, a := range aliases {
+		out[a.Package.ID] = append(out[a.Package.ID], a.Name)
+	}
+	return out, nil
+}
+
+// ListAliases returns the extra aliases of the package saved as name,
+// which may itself be an extra alias.
+func ListAliases(ctx context.Context, q data.Querier, name string) (data.Package, []string, error) {
+	pkg, err := getPackage(ctx, q, name)
+	if err != nil {
+		return data.Package{}, nil, err
+	}
+	rows, err := q.ListAliasesByPackage(ctx, pkg.ID)
+	if err != nil {
+		return pkg, nil, err
+	}
+	aliases := make([]string, len(rows))
+	for i, row := range rows {
+		aliases[i] = row.Name
+	}
+	return pkg, aliases, nil
+}
+
+// AliasOwner returns the live package that answers to name, either as its
+// primary alias or as an extra alias, and reports whether there is one.
+func AliasOwner(ctx context.Context, q data.Querier, name string) (data.Package, bool, error) {
+	pkg, err := q.GetPackageByName(ctx, name)
+	if err == nil {
+		return pkg, true, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return data.Package{}, false, err
+	}
+
+	alias, err := q.GetPackageAlias(ctx, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return data.Package{}, false, nil
+	}
+	if err != nil {
+		return data.Package{}, false, err
+	}
+	pkg, err = q.GetPackageByID(ctx, alias.PackageID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return data.Package{}, false, nil
+	}
+	return pkg, err == nil, err
+}
+
+// aliasTaken returns an error wrapping ErrAliasTaken that names the
+// package owning name.
+func aliasTaken(name string, owner data.Package) error {
+	if owner.Name == name {
+		return fmt.Errorf("%w: %s is the alias of %s", ErrAliasTaken, name, owner.Url)
+	}
+	return fmt.Errorf("%w: %s is an alias of %s (%s)", ErrAliasTaken, name, owner.Name, owner.Url)
+}
+
+// AddAliases gives the package saved as name the extra aliases in
+// aliases and returns the ones added. Aliases the package already answers
+// to are skipped; an alias owned by another package is an error naming
+// that package, and nothing is added.
+func AddAliases(ctx context.Context, q data.Querier, name string, aliases []string) ([]string, error) {
+	pkg, err := getPackage(ctx, q, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var add []string
+	for _, alias := range aliases {
+		if err := validateAlias(alias); err != nil {
+			return nil, err
+		}
+		owner, taken, err := AliasOwner(ctx, q, alias)
+		if err != nil {
+			return nil, err
+		}
+		if taken && owner.ID != pkg.ID {
+			return nil, aliasTaken(alias, owner)
+		}
+		if !taken && !slices.Contains(add, alias) {
+			add = append(add, alias)
+		}
+	}
+
+	for _, alias := range add {
+		// An alias left behind by a deleted package is free again.
+		if _, err := q.DeletePackageAlias(ctx, alias); err != nil {
+			return nil, err
+		}
+		if _, err := q.AddPackageAlias(ctx, data.AddPackageAliasParams{Name: alias, PackageID: pkg.ID}); err != nil {
+			return nil, err
+		}
+	}
+	return add, nil
+}
+
+// RemoveAliases removes extra aliases. Primary aliases cannot be removed
+// this way; the package is removed with 'gopk rm' instead.
+func RemoveAliases(ctx context.Context, q data.Querier, aliases []string) error {
+	var missing []string
+	for _, alias := range aliases {
+		n, err := q.DeletePackageAlias(ctx, alias)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			continue
+		}
+		if pkg, err := q.GetPackageByName(ctx, alias); err == nil {
+			return fmt.Errorf("%s is the primary alias of %s; remove the package with 'gopk rm'", alias, pkg.Url)
+		}
+		missing = append(missing, alias)
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrAliasNotFound, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// validateAlias rejects names that the commands taking aliases would not
+// read back as a single alias.
+func validateAlias(alias string) error {
+	switch {
+	case alias == "":
+		return errors.New("alias is required")
+	case strings.HasPrefix(alias, "@"):
+		return fmt.Errorf("invalid alias %q: @ introduces a group name", alias)
+	case strings.ContainsAny(alias, ", \t\n"):
+		return fmt.Errorf("invalid alias %q: aliases cannot contain spaces or commas", alias)
+	}
+	return nil
+}
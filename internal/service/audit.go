@@ -0,0 +1,64 @@
+package service
+
+import (
+	"github.com/lewvy/gopk/config"
+	"github.com/lewvy/gopk/internal/license"
+	"github.com/lewvy/gopk/internal/modcache"
+	"golang.org/x/mod/module"
+)
+
+type LicenseReport struct {
+	Module  string
+	Version string
+	License string
+	Direct  bool
+	Cached  bool
+	Status  LicenseStatus
+}
+
+// AuditLicenses classifies the license of every module required by the
+// go.mod governing dir, using the sources in the module cache, and checks
+// it against policy. Version replacements are followed; modules replaced
+// by a local directory are reported as unknown.
+func AuditLicenses(dir string, policy config.LicensePolicy) ([]LicenseReport, error) {
+	mf, err := readGoMod(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	replaced := make(map[string]module.Version)
+	for _, r := range mf.Replace {
+		if r.Old.Version == "" {
+			replaced[r.Old.Path] = r.New
+		} else {
+			replaced[r.Old.String()] = r.New
+		}
+	}
+
+	var reports []LicenseReport
+	for _, req := range mf.Require {
+		mod := req.Mod
+		if r, ok := replaced[mod.String()]; ok {
+			mod = r
+		} else if r, ok := replaced[mod.Path]; ok {
+			mod = r
+		}
+
+		report := LicenseReport{
+			Module:  req.Mod.Path,
+			Version: req.Mod.Version,
+			License: license.Unknown,
+			Direct:  !req.Indirect,
+		}
+		if mod.Version != "" {
+			if cached, err := modcache.Lookup(mod.Path, mod.Version); err == nil {
+				report.Cached = true
+				report.License = cached.License()
+			}
+		}
+		report.Status = CheckLicense(policy, report.License)
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/lewvy/gopk/internal/data"
+)
+
+// CompleteAliases returns the saved packages whose alias starts with
+// prefix, most frequently used first and, among equally used ones, most
+// recently used first. Packages of the read-only layers come last.
+func CompleteAliases(ctx context.Context, q data.Querier, prefix string) ([]data.Package, error) {
+	pkgs, err := List(ctx, q, -1, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []data.Package
+	for _, pkg := range pkgs {
+		if strings.HasPrefix(pkg.Name, prefix) {
+			out = append(out, pkg)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		// Layer packages have negative IDs.
+		if li, lj := out[i].ID < 0, out[j].ID < 0; li != lj {
+			return lj
+		}
+		return out[i].Freq.Int64 > out[j].Freq.Int64
+	})
+	return out, nil
+}
+
+// CompleteGroups returns the names of the groups that start with prefix.
+func CompleteGroups(ctx context.Context, q data.Querier, prefix string) ([]string, error) {
+	groups, err := ListGroups(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []string
+	for _, g := range groups {
+		if strings.HasPrefix(g.Name, prefix) {
+			out = append(out, g.Name)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/lewvy/gopk/internal/data"
+)
+
+// Group coverage levels reported by DiffProject.
+const (
+	CoverageFull    = "full"
+	CoveragePartial = "partial"
+	CoverageNone    = "none"
+)
+
+// ProjectDiff compares the go.mod of a project with the registry.
+type ProjectDiff struct {
+	Module string `json:"module"`
+
+	// Unsaved are the direct requirements with no saved package.
+	Unsaved []ProjectModule `json:"unsaved"`
+
+	// Drifted are the saved packages pinned to another version than the
+	// one the project requires.
+	Drifted []VersionDrift `json:"drifted"`
+
+	Groups []GroupCoverage `json:"groups"`
+}
+
+// ProjectModule is a requirement of the project.
+type ProjectModule struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+
+	// Alias is the alias InferAlias proposes for an unsaved module, empty
+	// when it is taken and the config refuses to qualify it.
+	Alias string `json:"alias,omitempty"`
+}
+
+// VersionDrift is a saved package whose pinned version differs from the
+// project's requirement.
+type VersionDrift struct {
+	Name    string `json:"name"`
+	Module  string `json:"module"`
+	Saved   string `json:"saved"`
+	Project string `json:"project"`
+}
+
+// GroupCoverage reports which members of a group the project requires.
+type GroupCoverage struct {
+	Name     string   `json:"name"`
+	Coverage string   `json:"coverage"`
+	Present  []string `json:"present"`
+	Missing  []string `json:"missing"`
+}
+
+// DiffProject compares the go.mod governing dir with the registry. A saved
+// package is present in the project when its module is required, directly
+// or not; only direct requirements are reported as unsaved.
+func DiffProject(ctx context.Context, q data.Querier, dir string) (ProjectDiff, error) {
+	mf, err := readGoMod(dir)
+	if err != nil {
+		return ProjectDiff{}, err
+	}
+
+	// Empty lists rather than nil ones, so that JSON output has arrays.
+	diff := ProjectDiff{Unsaved: []ProjectModule{}, Drifted: []VersionDrift{}, Groups: []GroupCoverage{}}
+	if mf.Module != nil {
+		diff.Module = mf.Module.Mod.Path
+	}
+	required := make(map[string]string, len(mf.Require))
+	for _, r := range mf.Require {
+		required[r.Mod.Path] = r.Mod.Version
+	}
+
+	pkgs, err := List(ctx, q, -1, false)
+	if err != nil {
+		return ProjectDiff{}, err
+	}
+	saved := make(map[string]bool, len(pkgs))
+	for _, pkg := range pkgs {
+		mod := ModulePath(pkg)
+		saved[mod] = true
+
+		have, ok := required[mod]
+		want := pkg.Version.String
+		if ok && want != "" && want != "latest" && want != have {
+			diff.Drifted = append(diff.Drifted, VersionDrift{Name: pkg.Name, Module: mod, Saved: want, Project: have})
+		}
+	}
+	sort.Slice(diff.Drifted, func(i, j int) bool { return diff.Drifted[i].Name < diff.Drifted[j].Name })
+
+	for _, r := range mf.Require {
+		if r.Indirect || saved[r.Mod.Path] {
+			continue
+		}
+		proposal, err := InferAlias(ctx, q, r.Mod.Path)
+		if err != nil && !errors.Is(err, ErrAliasTaken) {
+			return ProjectDiff{}, err
+		}
+		alias := proposal.Alias
+		if err != nil {
+			alias = ""
+		}
+		diff.Unsaved = append(diff.Unsaved, ProjectModule{Path: r.Mod.Path, Version: r.Mod.Version, Alias: alias})
+	}
+
+	groups, err := ListGroups(ctx, q)
+	if err != nil {
+		return ProjectDiff{}, err
+	}
+	for _, g := range groups {
+		members, err := q.ListPackagesByGroup(ctx, g.Name)
+		if err != nil {
+			return ProjectDiff{}, err
+		}
+		if len(members) == 0 {
+			continue
+		}
+
+		gc := GroupCoverage{Name: g.Name, Present: []string{}, Missing: []string{}}
+		for _, m := range members {
+			if _, ok := required[ModulePath(m)]; ok {
+				gc.Present = append(gc.Present, m.Name)
+			} else {
+				gc.Missing = append(gc.Missing, m.Name)
+			}
+		}
+		switch {
+		case len(gc.Missing) == 0:
+			gc.Coverage = CoverageFull
+		case len(gc.Present) == 0:
+			gc.Coverage = CoverageNone
+		default:
+			gc.Coverage = CoveragePartial
+		}
+		diff.Groups = append(diff.Groups, gc)
+	}
+
+	return diff, nil
+}
+
+// AddUnsaved saves each unsaved module of diff at the version the project
+// requires and returns the packages it added. Aliases are inferred as by
+// Add; modules whose alias is taken and cannot be qualified are skipped,
+// and reported in the returned error.
+func AddUnsaved(ctx context.Context, q data.Querier, diff ProjectDiff) ([]data.Package, error) {
+	var added []data.Package
+	var errs []error
+	for _, m := range diff.Unsaved {
+		pkg, err := Add(ctx, q, AddParams{URL: m.Path, Module: m.Path, Version: m.Version})
+		if errors.Is(err, ErrAliasTaken) {
+			errs = append(errs, fmt.Errorf("%s: %w; save it with 'gopk add %s --name <alias>'", m.Path, err, m.Path))
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", m.Path, err))
+			continue
+		}
+		added = append(added, pkg)
+	}
+	return added, errors.Join(errs...)
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lewvy/gopk/internal/data"
+	"github.com/lewvy/gopk/internal/modcache"
+	"golang.org/x/mod/modfile"
+)
+
+type EnrichResult struct {
+	Package data.Package
+	Info    data.PackageInfo
+	Err     error
+}
+
+// Enrich reads metadata for the named packages, or every saved package when
+// names is empty, from the local module cache and stores it. It never
+// downloads anything; packages that are not cached are reported with
+// modcache.ErrNotCached.
+func Enrich(ctx context.Context, q data.Querier, names []string) ([]EnrichResult, error) {
+	var pkgs []data.Package
+	var results []EnrichResult
+
+	if len(names) == 0 {
+		all, err := q.ListPackagesByLastUsed(ctx, -1)
+		if err != nil {
+			return nil, err
+		}
+		pkgs = all
+	} else {
+		for _, name := range names {
+			pkg, err := getPackage(ctx, q, name)
+			if err != nil {
+				results = append(results, EnrichResult{Package: data.Package{Name: name}, Err: err})
+				continue
+			}
+			pkgs = append(pkgs, pkg)
+		}
+	}
+
+	for _, pkg := range pkgs {
+		info, err := enrichPackage(ctx, q, pkg)
+		results = append(results, EnrichResult{Package: pkg, Info: info, Err: err})
+	}
+
+	return results, nil
+}
+
+func enrichPackage(ctx context.Context, q data.Querier, pkg data.Package) (data.PackageInfo, error) {
+	mod, err := modcache.Lookup(ModulePath(pkg), pkg.Version.String)
+	if err != nil {
+		return data.PackageInfo{}, err
+	}
+
+	params := data.UpsertPackageInfoParams{
+		PackageID:   pkg.ID,
+		Version:     mod.Version,
+		LicenseFile: mod.LicenseFile(),
+		License:     mod.License(),
+	}
+
+	gomod := filepath.Join(mod.Dir, "go.mod")
+	if content, err := os.ReadFile(gomod); err == nil {
+		if mf, err := modfile.ParseLax(gomod, content, nil); err == nil {
+			if mf.Go != nil {
+				params.GoVersion = mf.Go.Version
+			}
+			params.Deps = int64(len(mf.Require))
+		}
+	}
+
+	params.Synopsis = synopsis(mod.PackageDir(pkg.Url), pkg.Url)
+
+	return q.UpsertPackageInfo(ctx, params)
+}
+
+// synopsis returns the first sentence of the package documentation found in
+// dir, ignoring test files.
+func synopsis(dir, importPath string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+
+	fset := token.NewFileSet()
+	byPkg := make(map[string][]*ast.File)
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.PackageClauseOnly|parser.ParseComments)
+		if err != nil {
+			continue
+		}
+		byPkg[f.Name.Name] = append(byPkg[f.Name.Name], f)
+	}
+
+	// Prefer the package named after the directory, as go doc does.
+	files := byPkg[filepath.Base(dir)]
+	if files == nil {
+		for _, fs := range byPkg {
+			if len(fs) > len(files) {
+				files = fs
+			}
+		}
+	}
+	if len(files) == 0 {
+		return ""
+	}
+
+	p, err := doc.NewFromFiles(fset, files, importPath)
+	if err != nil {
+		return ""
+	}
+	return p.Synopsis(p.Doc)
+}
+
+// PackageInfo returns the stored metadata of every saved package, keyed by
+// package ID.
+func PackageInfo(ctx context.Context, q data.Querier) (map[int64]data.PackageInfo, error) {
+	rows, err := q.ListPackageInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	info := make(map[int64]data.PackageInfo, len(rows))
+	for _, row := range rows {
+		info[row.PackageID] = row
+	}
+	return info, nil
+}
@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+	"github.com/lewvy/gopk/internal/data"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// RegistryFileVersion is the version of the export format written by
+// Export.
+const RegistryFileVersion = 1
+
+// RegistryFile is the portable representation of a registry used for
+// export, import and gopkfile manifests.
+type RegistryFile struct {
+	Version  int           `json:"version" yaml:"version" toml:"version"`
+	Packages []FilePackage `json:"packages,omitempty" yaml:"packages,omitempty" toml:"packages,omitempty"`
+	Groups   []FileGroup   `json:"groups,omitempty" yaml:"groups,omitempty" toml:"groups,omitempty"`
+
+	// Templates is nil when the file has no templates key, which leaves
+	// the saved templates alone on a pruning import.
+	Templates []FileTemplate `json:"templates,omitempty" yaml:"templates,omitempty" toml:"templates,omitempty"`
+}
+
+type FilePackage struct {
+	Name       string        `json:"name" yaml:"name" toml:"name"`
+	URL        string        `json:"url" yaml:"url" toml:"url"`
+	Module     string        `json:"module,omitempty" yaml:"module,omitempty" toml:"module,omitempty"`
+	ImportName string        `json:"import_name,omitempty" yaml:"import_name,omitempty" toml:"import_name,omitempty"`
+	Kind       string        `json:"kind,omitempty" yaml:"kind,omitempty" toml:"kind,omitempty"`
+	Version    string        `json:"version,omitempty" yaml:"version,omitempty" toml:"version,omitempty"`
+	Aliases    []string      `json:"aliases,omitempty" yaml:"aliases,omitempty" toml:"aliases,omitempty"`
+	Snippets   []FileSnippet `json:"snippets,omitempty" yaml:"snippets,omitempty" toml:"snippets,omitempty"`
+}
+
+type FileSnippet struct {
+	Name string `json:"name" yaml:"name" toml:"name"`
+	Body string `json:"body" yaml:"body" toml:"body,multiline"`
+}
+
+type FileGroup struct {
+	Name     string   `json:"name" yaml:"name" toml:"name"`
+	Packages []string `json:"packages" yaml:"packages" toml:"packages"`
+}
+
+// FileTemplate is a project template with its files inlined.
+type FileTemplate struct {
+	Name        string             `json:"name" yaml:"name" toml:"name"`
+	Description string             `json:"description,omitempty" yaml:"description,omitempty" toml:"description,omitempty"`
+	Packages    []string           `json:"packages,omitempty" yaml:"packages,omitempty" toml:"packages,omitempty"`
+	Commands    []string           `json:"commands,omitempty" yaml:"commands,omitempty" toml:"commands,omitempty"`
+	Files       []FileTemplateFile `json:"files" yaml:"files" toml:"files"`
+}
+
+type FileTemplateFile struct {
+	Path string `json:"path" yaml:"path" toml:"path"`
+	Body string `json:"body" yaml:"body" toml:"body,multiline"`
+}
+
+func (ft FileTemplate) template() Template {
+	t := Template{Name: ft.Name, Description: ft.Description, Packages: ft.Packages, Commands: ft.Commands}
+	for _, f := range ft.Files {
+		t.Files = append(t.Files, TemplateFile{Path: f.Path, Body: f.Body})
+	}
+	return t
+}
+
+// FileTemplate returns t as written in registry files.
+func (t Template) FileTemplate() FileTemplate {
+	ft := FileTemplate{Name: t.Name, Description: t.Description, Packages: t.Packages, Commands: t.Commands}
+	for _, f := range t.Files {
+		ft.Files = append(ft.Files, FileTemplateFile{Path: f.Path, Body: f.Body})
+	}
+	return ft
+}
+
+var Formats = []string{"json", "yaml", "toml"}
+
+// FormatFromPath guesses the format of a registry file from its extension,
+// defaulting to TOML.
+func FormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return "json"
+	case ".yaml", ".yml":
+		return "yaml"
+	default:
+		return "toml"
+	}
+}
+
+// IsRegistryFile reports whether path has the extension of an export.
+func IsRegistryFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".yaml", ".yml", ".toml":
+		return true
+	}
+	return false
+}
+
+func (f RegistryFile) Encode(format string) ([]byte, error) {
+	switch format {
+	case "json":
+		b, err := json.MarshalIndent(f, "", "  ")
+		return append(b, '\n'), err
+	case "yaml":
+		return yaml.MarshalWithOptions(f, yaml.UseLiteralStyleIfMultiline(true))
+	case "toml":
+		return toml.Marshal(f)
+	default:
+		return nil, fmt.Errorf("unknown format %q (want one of %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+func DecodeRegistryFile(content []byte, format string) (RegistryFile, error) {
+	var f RegistryFile
+	var err error
+
+	switch format {
+	case "json":
+		err = json.Unmarshal(content, &f)
+	case "yaml":
+		err = yaml.Unmarshal(content, &f)
+	case "toml":
+		err = toml.Unmarshal(content, &f)
+	default:
+		return f, fmt.Errorf("unknown format %q (want one of %s)", format, strings.Join(Formats, ", "))
+	}
+	if err != nil {
+		return f, err
+	}
+
+	if f.Version > RegistryFileVersion {
+		return f, fmt.Errorf("registry file version %d is newer than supported version %d", f.Version, RegistryFileVersion)
+	}
+	return f, f.validate()
+}
+
+func (f RegistryFile) validate() error {
+	names := make(map[string]struct{})
+	for _, p := range f.Packages {
+		if p.Name == "" || p.URL == "" {
+			return fmt.Errorf("package %q: name and url are required", p.Name+p.URL)
+		}
+		if _, ok := names[p.Name]; ok {
+			return fmt.Errorf("package %q is declared twice", p.Name)
+		}
+		if _, err := normalizeKind(p.Kind); err != nil {
+			return fmt.Errorf("package %q: %w", p.Name, err)
+		}
+		names[p.Name] = struct{}{}
+	}
+	aliases := make(map[string]struct{})
+	for _, p := range f.Packages {
+		for _, alias := range p.Aliases {
+			if err := validateAlias(alias); err != nil {
+				return fmt.Errorf("package %q: %w", p.Name, err)
+			}
+			_, isName := names[alias]
+			if _, ok := aliases[alias]; ok || isName {
+				return fmt.Errorf("package %q: alias %q is declared twice", p.Name, alias)
+			}
+			aliases[alias] = struct{}{}
+		}
+	}
+
+	groups := make(map[string]struct{})
+	for _, g := range f.Groups {
+		if _, ok := groups[g.Name]; ok {
+			return fmt.Errorf("group %q is declared twice", g.Name)
+		}
+		groups[g.Name] = struct{}{}
+		for _, member := range g.Packages {
+			if _, ok := names[member]; !ok {
+				return fmt.Errorf("group %q: package %q is not declared", g.Name, member)
+			}
+		}
+	}
+
+	templates := make(map[string]struct{})
+	for _, t := range f.Templates {
+		if _, ok := templates[t.Name]; ok {
+			return fmt.Errorf("template %q is declared twice", t.Name)
+		}
+		templates[t.Name] = struct{}{}
+		if err := validateTemplate(t.template()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Export reads the registry into a RegistryFile, along with the project
+// templates. If group is not empty, only that group and its packages are
+// exported.
+func Export(ctx context.Context, q data.Querier, group string) (RegistryFile, error) {
+	f := RegistryFile{Version: RegistryFileVersion}
+
+	var pkgs []data.Package
+	var groups []data.Group
+	var err error
+
+	if group != "" {
+		if _, err := q.GetGroupIDByName(ctx, group); err != nil {
+			return f, fmt.Errorf("group not found: %s", group)
+		}
+		pkgs, err = q.ListPackagesByGroup(ctx, group)
+		groups = []data.Group{{Name: group}}
+	} else {
+		pkgs, err = q.ListPackagesByLastUsed(ctx, -1)
+		if err == nil {
+			groups, err = q.ListGroups(ctx)
+		}
+	}
+	if err != nil {
+		return f, err
+	}
+
+	sort.Slice(pkgs, func(i, j int) bool { return pkgs[i].Name < pkgs[j].Name })
+	for _, pkg := range pkgs {
+		fp, err := filePackage(ctx, q, pkg)
+		if err != nil {
+			return f, err
+		}
+		f.Packages = append(f.Packages, fp)
+	}
+
+	for _, g := range groups {
+		members, err := q.ListPackagesByGroup(ctx, g.Name)
+		if err != nil {
+			return f, err
+		}
+		fg := FileGroup{Name: g.Name, Packages: []string{}}
+		for _, m := range members {
+			fg.Packages = append(fg.Packages, m.Name)
+		}
+		f.Groups = append(f.Groups, fg)
+	}
+
+	if group == "" {
+		templates, err := ListTemplates()
+		if err != nil {
+			return f, err
+		}
+		for _, t := range templates {
+			f.Templates = append(f.Templates, t.FileTemplate())
+		}
+	}
+
+	return f, nil
+}
+
+func filePackage(ctx context.Context, q data.Querier, pkg data.Package) (FilePackage, error) {
+	fp := FilePackage{
+		Name:       pkg.Name,
+		URL:        pkg.Url,
+		ImportName: pkg.ImportName,
+		Version:    pkg.Version.String,
+	}
+	if pkg.Module != "" && pkg.Module != inferModule(pkg.Url) {
+		fp.Module = pkg.Module
+	}
+	if kind := KindOf(pkg); kind != KindLibrary {
+		fp.Kind = kind
+	}
+
+	aliases, err := q.ListAliasesByPackage(ctx, pkg.ID)
+	if err != nil {
+		return fp, err
+	}
+	for _, a := range aliases {
+		fp.Aliases = append(fp.Aliases, a.Name)
+	}
+
+	snippets, err := q.ListSnippetsByPackage(ctx, pkg.ID)
+	if err != nil {
+		return fp, err
+	}
+	for _, s := range snippets {
+		fp.Snippets = append(fp.Snippets, FileSnippet{Name: s.Name, Body: s.Body})
+	}
+	return fp, nil
+}
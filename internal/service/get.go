@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/lewvy/gopk/config"
+	"github.com/lewvy/gopk/internal/data"
+	"github.com/lewvy/gopk/internal/gotool"
+)
+
+// GetFromName installs the packages saved under pkgs. Packages that are
+// found are installed even if some aliases are missing; the returned error
+// then wraps ErrNotFound and suggests the closest aliases. With
+// opts.Fuzzy, a missing alias that matches exactly one package in a Search
+// stands for that package.
+func GetFromName(ctx context.Context, db data.Querier, pkgs []string, opts InstallOptions) error {
+	found, missing, err := resolveNames(ctx, db, pkgs, opts.Fuzzy)
+	if err != nil {
+		return err
+	}
+
+	if len(found) > 0 {
+		if err := InstallPackages(ctx, db, found, opts); err != nil {
+			return err
+		}
+	}
+
+	if len(missing) > 0 {
+		return notFound(ctx, db, missing)
+	}
+
+	return nil
+}
+
+// Resolve looks up aliases in the personal registry, primary or extra,
+// then in the read-only layers, and returns the packages found, in request
+// order, along with the aliases that are not saved anywhere. A name of the
+// form @group stands for the members of the group; it is missing when the
+// group has none.
+func Resolve(ctx context.Context, db data.Querier, names []string) ([]data.Package, []string, error) {
+	var grouped []data.Package
+	var missing []string
+	aliases := make([]string, 0, len(names))
+	for _, name := range names {
+		group, ok := strings.CutPrefix(name, "@")
+		if !ok {
+			aliases = append(aliases, name)
+			continue
+		}
+		members, err := db.ListPackagesByGroup(ctx, group)
+		if err != nil {
+			return nil, nil, fmt.Errorf("db error: %q", err)
+		}
+		if len(members) == 0 {
+			missing = append(missing, name)
+		}
+		grouped = append(grouped, members...)
+	}
+	names = aliases
+
+	rows, err := db.GetURLsByNames(ctx, names)
+	if err != nil {
+		return nil, nil, fmt.Errorf("db error: %q", err)
+	}
+
+	foundMap := make(map[string]data.Package)
+	byID := make(map[int64]data.Package)
+	for _, row := range rows {
+		foundMap[row.Name] = row
+		byID[row.ID] = row
+	}
+
+	// Rows found through an extra alias are keyed by that alias too. A live
+	// package's alias wins over the primary alias of a deleted package.
+	aliasRows, err := db.GetPackageAliasesByNames(ctx, names)
+	if err != nil {
+		return nil, nil, fmt.Errorf("db error: %q", err)
+	}
+	for _, a := range aliasRows {
+		if prev, exists := foundMap[a.Name]; !exists || prev.IsDeleted.Int64 != 0 {
+			if pkg, ok := byID[a.PackageID]; ok {
+				foundMap[a.Name] = pkg
+			}
+		}
+	}
+
+	layers, err := ConfiguredLayers()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var found []data.Package
+	for _, req := range names {
+		if pkg, exists := foundMap[req]; exists && pkg.IsDeleted.Int64 == 0 {
+			if slices.ContainsFunc(found, func(p data.Package) bool { return p.ID == pkg.ID }) {
+				continue
+			}
+			found = append(found, pkg)
+		} else if pkg, exists := layers.Lookup(req); exists {
+			found = append(found, pkg)
+		} else {
+			missing = append(missing, req)
+		}
+	}
+
+	// Group members come after the aliases, skipping packages that were
+	// also requested by alias or through another group.
+	for _, pkg := range grouped {
+		if !slices.ContainsFunc(found, func(p data.Package) bool { return p.Name == pkg.Name }) {
+			found = append(found, pkg)
+		}
+	}
+
+	return found, missing, nil
+}
+
+// resolveNames is Resolve, falling back to resolveFuzzy for the missing
+// names when fuzzy is set.
+func resolveNames(ctx context.Context, db data.Querier, names []string, fuzzy bool) ([]data.Package, []string, error) {
+	found, missing, err := Resolve(ctx, db, names)
+	if err != nil || !fuzzy || len(missing) == 0 {
+		return found, missing, err
+	}
+	more, missing, err := resolveFuzzy(ctx, db, missing)
+	return append(found, more...), missing, err
+}
+
+// InstallFromName installs the tools saved under names with 'go install'.
+// Like GetFromName, the tools that are found are installed even if some
+// aliases are missing.
+func InstallFromName(ctx context.Context, db data.Querier, names []string, opts InstallOptions) error {
+	found, missing, err := resolveNames(ctx, db, names, opts.Fuzzy)
+	if err != nil {
+		return err
+	}
+
+	if len(found) > 0 {
+		if err := InstallTools(ctx, db, found, opts); err != nil {
+			return err
+		}
+	}
+
+	if len(missing) > 0 {
+		return notFound(ctx, db, missing)
+	}
+
+	return nil
+}
+
+// InModule reports whether dir is inside a Go module.
+func InModule(dir string) bool {
+	_, err := findGoMod(dir)
+	return err == nil
+}
+
+// GetFromUrl runs 'go get' for module queries, such as those returned by
+// ModuleQueries, in the current module.
+func GetFromUrl(ctx context.Context, urls []string) error {
+	return getIn(ctx, "", urls)
+}
+
+// getIn is GetFromUrl for the module in dir; an empty dir is the current
+// directory.
+func getIn(ctx context.Context, dir string, urls []string) error {
+	if len(urls) == 0 {
+		return nil
+	}
+
+	runner, err := goRunner(dir)
+	if err != nil {
+		return err
+	}
+	if err := runner.Get(ctx, urls...); err != nil {
+		return fmt.Errorf("install failed: %w", err)
+	}
+	return nil
+}
+
+// Toolchain runs the go command. When nil, the go binary set in the config
+// file is run; tests replace it with a gotool.Fake.
+var Toolchain gotool.Runner
+
+// goRunner returns the Runner for the go command in dir, or in the current
+// directory when dir is empty.
+func goRunner(dir string) (gotool.Runner, error) {
+	if Toolchain != nil {
+		return Toolchain, nil
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	return gotool.Exec{Go: cfg.Go.Binary, Dir: dir}, nil
+}
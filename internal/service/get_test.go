@@ -0,0 +1,149 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/lewvy/gopk/internal/data"
+	"github.com/lewvy/gopk/internal/gotool"
+	"github.com/lewvy/gopk/internal/service"
+	"github.com/lewvy/gopk/internal/store"
+)
+
+// newRegistry returns an empty registry and installs a gotool.Fake as the
+// toolchain for the duration of the test.
+func newRegistry(t *testing.T, fake *gotool.Fake) data.Querier {
+	t.Helper()
+	t.Setenv("GOPK_CONFIG_DIR", t.TempDir())
+	t.Setenv("GOPK_PROFILE", "")
+
+	s, err := store.OpenJSON(filepath.Join(t.TempDir(), "packages.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	prev := service.Toolchain
+	service.Toolchain = fake
+	t.Cleanup(func() { service.Toolchain = prev })
+	return s
+}
+
+func add(t *testing.T, q data.Querier, p service.AddParams) {
+	t.Helper()
+	if _, err := service.Add(context.Background(), q, p); err != nil {
+		t.Fatalf("Add %s: %v", p.URL, err)
+	}
+}
+
+func checkCalls(t *testing.T, fake *gotool.Fake, want ...string) {
+	t.Helper()
+	var got []string
+	for _, call := range fake.Calls() {
+		got = append(got, call.String())
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("go calls:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestGetFromNameVersions(t *testing.T) {
+	fake := &gotool.Fake{}
+	q := newRegistry(t, fake)
+	add(t, q, service.AddParams{URL: "github.com/go-chi/chi/v5", Version: "v5.0.12"})
+	add(t, q, service.AddParams{URL: "github.com/go-chi/chi/v5/middleware", Name: "mw", Version: "v5.0.12"})
+	add(t, q, service.AddParams{URL: "github.com/spf13/cobra", Version: "latest"})
+	add(t, q, service.AddParams{URL: "github.com/rs/zerolog"})
+	add(t, q, service.AddParams{
+		URL:     "github.com/golangci/golangci-lint/cmd/golangci-lint",
+		Version: "v1.60.0",
+		Kind:    "tool",
+	})
+
+	err := service.GetFromName(context.Background(), q, []string{"chi", "mw", "cobra", "zerolog", "golangci-lint"}, service.InstallOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkCalls(t, fake,
+		"go get github.com/go-chi/chi/v5@v5.0.12 github.com/spf13/cobra github.com/rs/zerolog",
+		"go get -tool github.com/golangci/golangci-lint/cmd/golangci-lint@v1.60.0",
+	)
+}
+
+func TestGetFromNameMissing(t *testing.T) {
+	fake := &gotool.Fake{}
+	q := newRegistry(t, fake)
+	add(t, q, service.AddParams{URL: "github.com/go-chi/chi/v5", Version: "v5.0.12"})
+
+	err := service.GetFromName(context.Background(), q, []string{"chi", "chii", "@web"}, service.InstallOptions{})
+	if !errors.Is(err, service.ErrNotFound) {
+		t.Fatalf("got error %v, want ErrNotFound", err)
+	}
+	checkCalls(t, fake, "go get github.com/go-chi/chi/v5@v5.0.12")
+
+	fake = &gotool.Fake{}
+	service.Toolchain = fake
+	err = service.GetFromName(context.Background(), q, []string{"missing"}, service.InstallOptions{})
+	if !errors.Is(err, service.ErrNotFound) {
+		t.Fatalf("got error %v, want ErrNotFound", err)
+	}
+	checkCalls(t, fake)
+}
+
+func TestGetFromNameFailure(t *testing.T) {
+	broken := errors.New("no matching versions for query \"v9.9.9\"")
+	fake := &gotool.Fake{Fail: map[string]error{"github.com/spf13/cobra@v9.9.9": broken}}
+	q := newRegistry(t, fake)
+	add(t, q, service.AddParams{URL: "github.com/go-chi/chi/v5", Version: "v5.0.12"})
+	add(t, q, service.AddParams{URL: "github.com/spf13/cobra", Version: "v9.9.9"})
+
+	err := service.GetFromName(context.Background(), q, []string{"chi", "cobra"}, service.InstallOptions{})
+	var goErr *gotool.Error
+	if !errors.As(err, &goErr) || !errors.Is(err, broken) {
+		t.Fatalf("got error %v, want the go get failure", err)
+	}
+	checkCalls(t, fake, "go get github.com/go-chi/chi/v5@v5.0.12 github.com/spf13/cobra@v9.9.9")
+}
+
+func TestInstallFromNamePartialFailure(t *testing.T) {
+	broken := errors.New("module not found")
+	fake := &gotool.Fake{Fail: map[string]error{"example.com/broken/cmd/broken": broken}}
+	q := newRegistry(t, fake)
+	add(t, q, service.AddParams{URL: "golang.org/x/tools/cmd/stringer", Version: "v0.24.0", Kind: "tool"})
+	add(t, q, service.AddParams{URL: "example.com/broken/cmd/broken", Kind: "tool"})
+	add(t, q, service.AddParams{URL: "mvdan.cc/gofumpt", Kind: "both"})
+
+	err := service.InstallFromName(context.Background(), q, []string{"broken", "stringer", "gofumpt", "missing"}, service.InstallOptions{})
+	if !errors.Is(err, broken) {
+		t.Fatalf("got error %v, want the go install failure", err)
+	}
+	checkCalls(t, fake,
+		"go install example.com/broken/cmd/broken@latest",
+		"go install golang.org/x/tools/cmd/stringer@v0.24.0",
+		"go install mvdan.cc/gofumpt@latest",
+	)
+}
+
+func TestResolveAliasOfDeletedName(t *testing.T) {
+	q := newRegistry(t, &gotool.Fake{})
+	ctx := context.Background()
+	add(t, q, service.AddParams{URL: "github.com/sirupsen/logrus", Name: "log"})
+	add(t, q, service.AddParams{URL: "go.uber.org/zap"})
+	if _, err := service.DeletePackage(ctx, q, []string{"log"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.AddAliases(ctx, q, "zap", []string{"log"}); err != nil {
+		t.Fatal(err)
+	}
+
+	found, missing, err := service.Resolve(ctx, q, []string{"log"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(missing) != 0 || len(found) != 1 || found[0].Name != "zap" {
+		t.Errorf("Resolve(log): got %v, missing %v, want zap", found, missing)
+	}
+}
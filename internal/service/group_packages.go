@@ -3,10 +3,10 @@ package service
 import (
 	"context"
 
-	"github.com/lewvy/gopk/cmd/internal/data"
+	"github.com/lewvy/gopk/internal/data"
 )
 
-func RemovePackagesFromGroups(ctx context.Context, queries *data.Queries, pkgs map[data.Package]struct{}, group int64) error {
+func RemovePackagesFromGroups(ctx context.Context, queries data.Querier, pkgs map[data.Package]struct{}, group int64) error {
 
 	pkgIDs := []int64{}
 
@@ -5,10 +5,10 @@ import (
 	"fmt"
 	"sort"
 
-	"github.com/lewvy/gopk/cmd/internal/data"
+	"github.com/lewvy/gopk/internal/data"
 )
 
-func CreateGroup(q *data.Queries, name string) error {
+func CreateGroup(q data.Querier, name string) error {
 	ctx := context.Background()
 	_, err := q.CreateGroup(ctx, name)
 	if err != nil {
@@ -17,12 +17,11 @@ func CreateGroup(q *data.Queries, name string) error {
 	return nil
 }
 
-func ListGroups(q *data.Queries) ([]data.Group, error) {
-	ctx := context.Background()
+func ListGroups(ctx context.Context, q data.Querier) ([]data.Group, error) {
 	return q.ListGroups(ctx)
 }
 
-func ListPackagesByGroupOrderByFreq(ctx context.Context, q *data.Queries, group string) ([]data.Package, error) {
+func ListPackagesByGroupOrderByFreq(ctx context.Context, q data.Querier, group string) ([]data.Package, error) {
 	pkgs, err := q.ListPackagesByGroup(ctx, group)
 	if err != nil {
 		return nil, err
@@ -34,7 +33,7 @@ func ListPackagesByGroupOrderByFreq(ctx context.Context, q *data.Queries, group
 
 }
 
-func ListPackagesByGroupOrderByLU(ctx context.Context, queries *data.Queries, groupName string) ([]data.Package, error) {
+func ListPackagesByGroupOrderByLU(ctx context.Context, queries data.Querier, groupName string) ([]data.Package, error) {
 	pkgs, err := queries.ListPackagesByGroup(ctx, groupName)
 	if err != nil {
 		return nil, err
@@ -45,7 +44,7 @@ func ListPackagesByGroupOrderByLU(ctx context.Context, queries *data.Queries, gr
 	return pkgs, nil
 }
 
-func AssignToGroup(q *data.Queries, pkgs []string, group string) error {
+func AssignToGroup(q data.Querier, pkgs []string, group string) error {
 	ctx := context.Background()
 
 	groupID, err := q.GetGroupIDByName(ctx, group)
@@ -70,15 +69,10 @@ func AssignToGroup(q *data.Queries, pkgs []string, group string) error {
 	return nil
 }
 
-func InstallGroup(ctx context.Context, q *data.Queries, groupName string) error {
+func InstallGroup(ctx context.Context, q data.Querier, groupName string, opts InstallOptions) error {
 	pkgs, err := ListPackagesByGroupOrderByFreq(ctx, q, groupName)
 	if err != nil {
 		return err
 	}
-	urls := []string{}
-	for _, pkg := range pkgs {
-		urls = append(urls, pkg.Url)
-	}
-
-	return GetFromUrl(urls)
+	return InstallPackages(ctx, q, pkgs, opts)
 }
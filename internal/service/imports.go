@@ -0,0 +1,210 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/lewvy/gopk/internal/data"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+var ErrNoGoMod = errors.New("no go.mod found")
+
+// Import adds the packages saved under names to the Go source in src and
+// returns the formatted result. When get is set, modules that the enclosing
+// go.mod does not require yet are fetched with 'go get'.
+func Import(ctx context.Context, q data.Querier, names []string, filename string, src []byte, get bool) ([]byte, error) {
+	pkgs, missing, err := Resolve(ctx, q, names)
+	if err != nil {
+		return nil, err
+	}
+	if len(missing) > 0 {
+		return nil, notFound(ctx, q, missing)
+	}
+
+	out, err := AddImports(filename, src, pkgs)
+	if err != nil {
+		return nil, err
+	}
+
+	if get {
+		dir := "."
+		if filename != "" {
+			dir = filepath.Dir(filename)
+		}
+		mods, err := missingModules(dir, pkgs)
+		if err != nil {
+			return nil, err
+		}
+		if err := GetFromUrl(ctx, mods); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, pkg := range pkgs {
+		if err := q.UpdatePackageUsage(ctx, pkg.Url); err != nil {
+			return nil, err
+		}
+	}
+
+	return out, nil
+}
+
+// AddImports inserts an import spec for each package into the file, using
+// the stored import name when there is one. Imports are merged into the
+// closest matching group and sorted the way goimports does.
+func AddImports(filename string, src []byte, pkgs []data.Package) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pkg := range pkgs {
+		astutil.AddNamedImport(fset, f, pkg.ImportName, pkg.Url)
+	}
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, f); err != nil {
+		return nil, err
+	}
+	return groupImports(filename, buf.Bytes())
+}
+
+// groupImports splits each run of imports into a standard library group
+// followed by a third-party group, separated by a blank line, in the same
+// way goimports does. src must already be gofmt-ed.
+func groupImports(filename string, src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filename, src, parser.ImportsOnly|parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.SplitAfter(string(src), "\n")
+	breaks := make(map[int]bool)
+
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT || !gen.Lparen.IsValid() {
+			continue
+		}
+
+		// Runs are specs on successive lines; blank lines and comments
+		// end a run and are left untouched.
+		start := 0
+		for i := 1; i <= len(gen.Specs); i++ {
+			if i < len(gen.Specs) && fset.Position(gen.Specs[i].Pos()).Line == fset.Position(gen.Specs[i-1].End()).Line+1 &&
+				gen.Specs[i].(*ast.ImportSpec).Doc == nil {
+				continue
+			}
+			run := gen.Specs[start:i]
+			first := fset.Position(run[0].Pos()).Line - 1
+
+			var std, other []string
+			for j, spec := range run {
+				line := lines[first+j]
+				if isStdImport(importPath(spec.(*ast.ImportSpec))) {
+					std = append(std, line)
+				} else {
+					other = append(other, line)
+				}
+			}
+			copy(lines[first:], append(std, other...))
+			if len(std) > 0 && len(other) > 0 {
+				breaks[first+len(std)] = true
+			}
+			start = i
+		}
+	}
+
+	var buf bytes.Buffer
+	for i, line := range lines {
+		if breaks[i] {
+			buf.WriteString("\n")
+		}
+		buf.WriteString(line)
+	}
+	return format.Source(buf.Bytes())
+}
+
+func importPath(spec *ast.ImportSpec) string {
+	path, err := strconv.Unquote(spec.Path.Value)
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+// isStdImport reports whether path belongs to the standard library, using
+// the same rule as goimports: the first element has no dot.
+func isStdImport(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
+
+// missingModules returns the 'go get' queries for the modules of pkgs that
+// are not required by the go.mod governing dir.
+func missingModules(dir string, pkgs []data.Package) ([]string, error) {
+	mf, err := readGoMod(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	have := make(map[string]struct{})
+	if mf.Module != nil {
+		have[mf.Module.Mod.Path] = struct{}{}
+	}
+	for _, r := range mf.Require {
+		have[r.Mod.Path] = struct{}{}
+	}
+
+	var missing []data.Package
+	for _, pkg := range pkgs {
+		if _, ok := have[ModulePath(pkg)]; !ok {
+			missing = append(missing, pkg)
+		}
+	}
+	return ModuleQueries(missing), nil
+}
+
+// readGoMod parses the go.mod governing dir.
+func readGoMod(dir string) (*modfile.File, error) {
+	path, err := findGoMod(dir)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return modfile.ParseLax(path, content, nil)
+}
+
+func findGoMod(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		path := filepath.Join(dir, "go.mod")
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ErrNoGoMod
+		}
+		dir = parent
+	}
+}
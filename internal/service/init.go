@@ -0,0 +1,264 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/lewvy/gopk/internal/data"
+	"golang.org/x/tools/imports"
+)
+
+// InitOptions control InitModule.
+type InitOptions struct {
+	// Group is installed into the new module at the saved versions.
+	Group string
+
+	// Main writes a main.go assembled from one snippet of each member of
+	// Group: the one named Snippet, or the first one by name when Snippet
+	// is empty.
+	Main    bool
+	Snippet string
+
+	// Template is the text/template source of main.go. It is executed
+	// with a ProjectData and takes precedence over Main.
+	Template string
+
+	InstallOptions
+}
+
+// ProjectData is passed to the templates of new projects.
+type ProjectData struct {
+	// Module is the module path and Name its last element, without a
+	// major version suffix.
+	Module string
+	Name   string
+
+	Packages []ProjectPackage
+}
+
+// ProjectPackage is a saved package as seen by templates.
+type ProjectPackage struct {
+	Name       string
+	Path       string
+	ImportName string
+	Version    string
+	// Snippets maps snippet names to their bodies.
+	Snippets map[string]string
+}
+
+// InitModule creates a module with the given path in dir, which is created
+// if needed and must not hold a go.mod yet, then writes main.go and
+// installs the group as set by opts.
+func InitModule(ctx context.Context, q data.Querier, dir, modulePath string, opts InitOptions) error {
+	if err := checkNewModuleDir(dir); err != nil {
+		return err
+	}
+
+	var pkgs []data.Package
+	if opts.Group != "" {
+		var err error
+		pkgs, err = ListPackagesByGroupOrderByFreq(ctx, q, opts.Group)
+		if err != nil {
+			return err
+		}
+		if len(pkgs) == 0 {
+			return fmt.Errorf("group %s has no packages", opts.Group)
+		}
+	}
+
+	// Render main.go before touching the file system, so that a broken
+	// template or snippet leaves nothing behind.
+	var mainGo []byte
+	var err error
+	switch {
+	case opts.Template != "":
+		mainGo, err = renderMain(ctx, q, dir, modulePath, pkgs, opts.Template)
+	case opts.Main:
+		mainGo, err = assembleMain(ctx, q, dir, pkgs, opts.Snippet)
+	}
+	if err != nil {
+		return err
+	}
+
+	files := make(map[string][]byte)
+	if mainGo != nil {
+		files["main.go"] = mainGo
+	}
+	return createModule(ctx, q, dir, modulePath, files, pkgs, opts.InstallOptions)
+}
+
+func checkNewModuleDir(dir string) error {
+	if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+		return fmt.Errorf("%s already contains a go.mod", dir)
+	}
+	return nil
+}
+
+// createModule creates dir, runs 'go mod init' in it, writes files, keyed
+// by slash-separated paths, and installs pkgs into the new module.
+func createModule(ctx context.Context, q data.Querier, dir, modulePath string, files map[string][]byte, pkgs []data.Package, opts InstallOptions) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	runner, err := goRunner(dir)
+	if err != nil {
+		return err
+	}
+	if err := runner.ModInit(ctx, modulePath); err != nil {
+		return fmt.Errorf("go mod init failed: %w", err)
+	}
+
+	for _, p := range sortedPaths(files) {
+		path := filepath.Join(dir, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, files[p], 0644); err != nil {
+			return err
+		}
+	}
+
+	opts.Dir = dir
+	return InstallPackages(ctx, q, pkgs, opts)
+}
+
+// Package returns the package saved under name, or the zero
+// ProjectPackage. Templates use it as {{(.Package "zap").Path}}.
+func (d ProjectData) Package(name string) ProjectPackage {
+	for _, p := range d.Packages {
+		if p.Name == name {
+			return p
+		}
+	}
+	return ProjectPackage{}
+}
+
+// Snippet returns the body of a snippet of the package saved under pkg,
+// or "". Templates use it as {{.Snippet "zap" "setup"}}.
+func (d ProjectData) Snippet(pkg, name string) string {
+	return d.Package(pkg).Snippets[name]
+}
+
+// ProjectName returns the last element of modulePath without its major
+// version suffix: svc for github.com/me/svc/v2. It names the project and
+// its directory by default.
+func ProjectName(modulePath string) string {
+	name := path.Base(modulePath)
+	if moduleVerRe.MatchString(name) {
+		name = path.Base(path.Dir(modulePath))
+	}
+	return name
+}
+
+// NewProjectData returns the template data for a module with the given
+// path using pkgs.
+func NewProjectData(ctx context.Context, q data.Querier, modulePath string, pkgs []data.Package) (ProjectData, error) {
+	d := ProjectData{Module: modulePath, Name: ProjectName(modulePath)}
+	for _, pkg := range pkgs {
+		snippets, err := q.ListSnippetsByPackage(ctx, pkg.ID)
+		if err != nil {
+			return ProjectData{}, err
+		}
+		pp := ProjectPackage{
+			Name:       pkg.Name,
+			Path:       pkg.Url,
+			ImportName: pkg.ImportName,
+			Version:    pkg.Version.String,
+			Snippets:   make(map[string]string, len(snippets)),
+		}
+		for _, sn := range snippets {
+			pp.Snippets[sn.Name] = sn.Body
+		}
+		d.Packages = append(d.Packages, pp)
+	}
+	return d, nil
+}
+
+func renderMain(ctx context.Context, q data.Querier, dir, modulePath string, pkgs []data.Package, text string) ([]byte, error) {
+	d, err := NewProjectData(ctx, q, modulePath, pkgs)
+	if err != nil {
+		return nil, err
+	}
+	out, err := execute("main.go", text, d)
+	if err != nil {
+		return nil, err
+	}
+	return fixImports(filepath.Join(dir, "main.go"), out)
+}
+
+// assembleMain builds a main package from one snippet of each package:
+// snippets that are top-level declarations are placed before main, the
+// others inside it, in group order.
+func assembleMain(ctx context.Context, q data.Querier, dir string, pkgs []data.Package, name string) ([]byte, error) {
+	var decls, stmts []string
+	var used []data.Package
+	for _, pkg := range pkgs {
+		snippets, err := q.ListSnippetsByPackage(ctx, pkg.ID)
+		if err != nil {
+			return nil, err
+		}
+		i := 0
+		if name != "" {
+			i = -1
+			for j, sn := range snippets {
+				if sn.Name == name {
+					i = j
+				}
+			}
+		}
+		if i < 0 || i >= len(snippets) {
+			continue
+		}
+
+		body := snippets[i].Body
+		if isDeclSnippet(body) {
+			decls = append(decls, body)
+		} else {
+			stmts = append(stmts, body)
+		}
+		if IsLibrary(pkg) {
+			used = append(used, pkg)
+		}
+	}
+	if len(decls)+len(stmts) == 0 {
+		return nil, errors.New("no snippets to build main.go from; add some with 'gopk snippet add'")
+	}
+
+	var src strings.Builder
+	src.WriteString("package main\n\n")
+	for _, d := range decls {
+		src.WriteString(d + "\n\n")
+	}
+	src.WriteString("func main() {\n" + strings.Join(stmts, "\n\n") + "\n}\n")
+
+	filename := filepath.Join(dir, "main.go")
+	out, err := AddImports(filename, []byte(src.String()), used)
+	if err != nil {
+		return nil, fmt.Errorf("assembling main.go: %w", err)
+	}
+	return fixImports(filename, out)
+}
+
+// isDeclSnippet reports whether body is a list of top-level declarations
+// rather than statements.
+func isDeclSnippet(body string) bool {
+	_, err := parser.ParseFile(token.NewFileSet(), "", "package p\n"+body, parser.SkipObjectResolution)
+	return err == nil
+}
+
+// fixImports adds the standard library imports that src needs and removes
+// unused ones, as goimports does, and formats the result.
+func fixImports(filename string, src []byte) ([]byte, error) {
+	out, err := imports.Process(filename, src, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(filename), err)
+	}
+	return out, nil
+}
@@ -0,0 +1,23 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/lewvy/gopk/internal/service"
+)
+
+func TestProjectName(t *testing.T) {
+	tests := []struct {
+		module, want string
+	}{
+		{"github.com/me/svc", "svc"},
+		{"github.com/me/svc/v2", "svc"},
+		{"github.com/me/svc/cmd/v2ctl", "v2ctl"},
+		{"svc", "svc"},
+	}
+	for _, tt := range tests {
+		if got := service.ProjectName(tt.module); got != tt.want {
+			t.Errorf("ProjectName(%s) = %s, want %s", tt.module, got, tt.want)
+		}
+	}
+}
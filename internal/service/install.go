@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lewvy/gopk/config"
+	"github.com/lewvy/gopk/internal/data"
+	"github.com/lewvy/gopk/internal/license"
+	"github.com/lewvy/gopk/internal/modcache"
+)
+
+var ErrPolicy = errors.New("blocked by policy")
+
+// Warn reports problems that do not stop an install. It prints to stderr
+// by default; the TUI replaces it to show warnings in its status line.
+var Warn = func(msg string) {
+	fmt.Fprintln(os.Stderr, "warning:", msg)
+}
+
+type InstallOptions struct {
+	// FailOnVuln refuses to install versions with known vulnerabilities.
+	// It is also enabled by fail_on_vuln in the config file.
+	FailOnVuln bool
+
+	// Local replaces each module with the fork or local directory saved
+	// for it, as if every package had replace_always set. It has no
+	// effect on 'go install'.
+	Local bool
+
+	// Dir is the directory of the module to install into. It defaults
+	// to the current directory.
+	Dir string
+
+	// Fuzzy lets GetFromName and InstallFromName resolve a missing alias
+	// to the only package it fuzzy matches.
+	Fuzzy bool
+}
+
+// InstallPackages checks pkgs against the configured policies and the
+// vulnerability database, then adds them to the current module at the
+// saved versions: libraries with 'go get', tools with 'go get -tool'.
+// Saved replacements are added to go.mod first, so that forks and local
+// checkouts do not need to be published.
+func InstallPackages(ctx context.Context, q data.Querier, pkgs []data.Package, opts InstallOptions) error {
+	if len(pkgs) == 0 {
+		return nil
+	}
+
+	if err := checkPolicies(ctx, q, pkgs, opts); err != nil {
+		return err
+	}
+
+	if err := addReplaces(ctx, opts.Dir, replaced(pkgs, opts.Local)); err != nil {
+		return err
+	}
+
+	var libs, tools []data.Package
+	for _, pkg := range pkgs {
+		if IsLibrary(pkg) {
+			libs = append(libs, pkg)
+		}
+		if IsTool(pkg) {
+			tools = append(tools, pkg)
+		}
+	}
+
+	if err := getIn(ctx, opts.Dir, ModuleQueries(libs)); err != nil {
+		return err
+	}
+	if len(tools) == 0 {
+		return nil
+	}
+
+	queries := []string{"-tool"}
+	for _, pkg := range tools {
+		queries = append(queries, ToolQuery(pkg))
+	}
+	return getIn(ctx, opts.Dir, queries)
+}
+
+// InstallTools checks pkgs like InstallPackages, then installs their
+// binaries globally with 'go install'. Each tool is installed separately,
+// so one failure does not prevent the others from being installed.
+func InstallTools(ctx context.Context, q data.Querier, pkgs []data.Package, opts InstallOptions) error {
+	var notTools []string
+	for _, pkg := range pkgs {
+		if !IsTool(pkg) {
+			notTools = append(notTools, pkg.Name)
+		}
+	}
+	if len(notTools) > 0 {
+		return fmt.Errorf("not a tool: %s (save it with --kind tool or both)", strings.Join(notTools, ", "))
+	}
+
+	if err := checkPolicies(ctx, q, pkgs, opts); err != nil {
+		return err
+	}
+
+	runner, err := goRunner("")
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, pkg := range pkgs {
+		if err := runner.Install(ctx, ToolQuery(pkg)); err != nil {
+			errs = append(errs, fmt.Errorf("install %s failed: %w", pkg.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func checkPolicies(ctx context.Context, q data.Querier, pkgs []data.Package, opts InstallOptions) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	if err := checkLicenses(ctx, q, cfg.License, pkgs); err != nil {
+		return err
+	}
+
+	return checkVulns(cfg, pkgs, opts.FailOnVuln || cfg.Vuln.FailOnVuln)
+}
+
+func checkLicenses(ctx context.Context, q data.Querier, policy config.LicensePolicy, pkgs []data.Package) error {
+	if !policy.Enabled() {
+		return nil
+	}
+
+	var blocked []string
+	for _, pkg := range pkgs {
+		lic := packageLicense(ctx, q, pkg)
+		status := CheckLicense(policy, lic)
+
+		switch {
+		case status == LicenseAllowed:
+			continue
+		case status == LicenseUnknown && !policy.BlockUnknown:
+			Warn(fmt.Sprintf("%s: license unknown (run 'gopk enrich %s' once the module is cached)", pkg.Name, pkg.Name))
+			continue
+		}
+
+		msg := fmt.Sprintf("%s: license %s is not allowed", pkg.Name, lic)
+		if policy.Blocking() {
+			blocked = append(blocked, msg)
+		} else {
+			Warn(msg)
+		}
+	}
+
+	if len(blocked) > 0 {
+		return fmt.Errorf("%w: %s", ErrPolicy, strings.Join(blocked, "; "))
+	}
+	return nil
+}
+
+type LicenseStatus int
+
+const (
+	LicenseAllowed LicenseStatus = iota
+	LicenseUnknown
+	LicenseDenied
+)
+
+func (s LicenseStatus) String() string {
+	switch s {
+	case LicenseAllowed:
+		return "ok"
+	case LicenseUnknown:
+		return "unknown"
+	default:
+		return "denied"
+	}
+}
+
+// CheckLicense evaluates a license identifier against policy.
+func CheckLicense(policy config.LicensePolicy, lic string) LicenseStatus {
+	if lic == "" || lic == license.Unknown {
+		return LicenseUnknown
+	}
+	if license.Matches(lic, policy.Deny) {
+		return LicenseDenied
+	}
+	if len(policy.Allow) > 0 && !license.Matches(lic, policy.Allow) {
+		return LicenseDenied
+	}
+	return LicenseAllowed
+}
+
+// packageLicense returns the license recorded by enrich, falling back to
+// the module cache for packages that have not been enriched.
+func packageLicense(ctx context.Context, q data.Querier, pkg data.Package) string {
+	if info, err := q.GetPackageInfo(ctx, pkg.ID); err == nil && info.License != "" {
+		return info.License
+	}
+	mod, err := modcache.Lookup(ModulePath(pkg), pkg.Version.String)
+	if err != nil {
+		return license.Unknown
+	}
+	return mod.License()
+}
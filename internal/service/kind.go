@@ -0,0 +1,61 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lewvy/gopk/internal/data"
+)
+
+// Package kinds. Libraries are added to a module with 'go get', tools
+// with 'go get -tool' or installed globally with 'go install'.
+const (
+	KindLibrary = "library"
+	KindTool    = "tool"
+	KindBoth    = "both"
+)
+
+// Kinds lists the valid kinds, for flags and error messages.
+var Kinds = []string{KindLibrary, KindTool, KindBoth}
+
+func normalizeKind(kind string) (string, error) {
+	switch kind {
+	case "":
+		return KindLibrary, nil
+	case KindLibrary, KindTool, KindBoth:
+		return kind, nil
+	default:
+		return "", fmt.Errorf("invalid kind %q (want one of %s)", kind, strings.Join(Kinds, ", "))
+	}
+}
+
+// KindOf returns the kind of pkg. Rows saved before kinds existed are
+// libraries.
+func KindOf(pkg data.Package) string {
+	if pkg.Kind == "" {
+		return KindLibrary
+	}
+	return pkg.Kind
+}
+
+// IsLibrary reports whether pkg is imported as a library.
+func IsLibrary(pkg data.Package) bool {
+	return KindOf(pkg) != KindTool
+}
+
+// IsTool reports whether pkg is a command that can be installed.
+func IsTool(pkg data.Package) bool {
+	return pkg.Kind == KindTool || pkg.Kind == KindBoth
+}
+
+// ToolQuery returns the argument to 'go get -tool' or 'go install' for
+// pkg. Unlike ModuleQuery it names the package, since a tool is a main
+// package that may live below the module root. 'go install' requires a
+// version, so "latest" is kept.
+func ToolQuery(pkg data.Package) string {
+	v := pkg.Version.String
+	if v == "" {
+		v = "latest"
+	}
+	return pkg.Url + "@" + v
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/lewvy/gopk/config"
+	"github.com/lewvy/gopk/internal/data"
+	"github.com/lewvy/gopk/internal/sqlite"
+)
+
+// PersonalLayer is the name of the writable personal database in the
+// layer stack.
+const PersonalLayer = "personal"
+
+// Layer is a read-only registry stacked under the personal database.
+type Layer struct {
+	Name     string
+	Path     string
+	Packages []data.Package
+}
+
+// Layers are the read-only registries in order of precedence. Packages
+// loaded from layers are given negative IDs so they never collide with
+// rows of the personal database.
+type Layers []Layer
+
+var loadLayersOnce = sync.OnceValues(func() (Layers, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	return LoadLayers(cfg)
+})
+
+// ConfiguredLayers returns the layers from the config file, loading them
+// on first use.
+func ConfiguredLayers() (Layers, error) {
+	return loadLayersOnce()
+}
+
+func LoadLayers(cfg config.Config) (Layers, error) {
+	var layers Layers
+	nextID := int64(-1)
+
+	for _, lc := range cfg.Layers {
+		pkgs, err := loadLayer(lc.Path)
+		if err != nil {
+			return nil, fmt.Errorf("layer %s: %w", lc.Name, err)
+		}
+		for i := range pkgs {
+			pkgs[i].ID = nextID
+			nextID--
+		}
+		layers = append(layers, Layer{Name: lc.Name, Path: lc.Path, Packages: pkgs})
+	}
+
+	return layers, nil
+}
+
+func loadLayer(path string) ([]data.Package, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".db", ".sqlite", ".sqlite3":
+		return loadDBLayer(path)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := DecodeRegistryFile(content, FormatFromPath(path))
+	if err != nil {
+		return nil, err
+	}
+
+	pkgs := make([]data.Package, 0, len(f.Packages))
+	for _, fp := range f.Packages {
+		fp = normalizeFilePackage(fp)
+		pkgs = append(pkgs, data.Package{
+			Name:       fp.Name,
+			Url:        fp.URL,
+			Module:     fp.Module,
+			ImportName: fp.ImportName,
+			Kind:       fp.Kind,
+			Version:    sql.NullString{Valid: true, String: fp.Version},
+		})
+	}
+	return pkgs, nil
+}
+
+func loadDBLayer(path string) ([]data.Package, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	db, err := sql.Open(sqlite.Driver, "file:"+path+"?mode=ro")
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	pkgs, err := data.New(db).ListPackagesByLastUsed(context.Background(), -1)
+	if err != nil && strings.Contains(err.Error(), "no such column") {
+		return nil, fmt.Errorf("%w: the database was created by an older gopk; open it once with this version to migrate it, or use 'gopk export' to turn it into a gopkfile", err)
+	}
+	return pkgs, err
+}
+
+// Of returns the name of the layer pkg was loaded from, or PersonalLayer
+// for packages of the personal database.
+func (ls Layers) Of(pkg data.Package) string {
+	if pkg.ID >= 0 {
+		return PersonalLayer
+	}
+	for _, l := range ls {
+		for _, p := range l.Packages {
+			if p.ID == pkg.ID {
+				return l.Name
+			}
+		}
+	}
+	return PersonalLayer
+}
+
+// Lookup returns the first layer package saved under alias.
+func (ls Layers) Lookup(alias string) (data.Package, bool) {
+	for _, l := range ls {
+		for _, p := range l.Packages {
+			if p.Name == alias {
+				return p, true
+			}
+		}
+	}
+	return data.Package{}, false
+}
+
+// Merge appends the layer packages that are not shadowed by personal or by
+// a layer of higher precedence. An alias in the personal database always
+// wins.
+func (ls Layers) Merge(personal []data.Package) []data.Package {
+	if len(ls) == 0 {
+		return personal
+	}
+
+	seen := make(map[string]struct{}, len(personal))
+	for _, p := range personal {
+		seen[p.Name] = struct{}{}
+	}
+
+	merged := append([]data.Package(nil), personal...)
+	for _, l := range ls {
+		for _, p := range l.Packages {
+			if _, ok := seen[p.Name]; ok {
+				continue
+			}
+			seen[p.Name] = struct{}{}
+			merged = append(merged, p)
+		}
+	}
+	return merged
+}
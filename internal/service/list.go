@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+
+	"github.com/lewvy/gopk/internal/data"
+)
+
+// List returns the saved packages, followed by the packages of read-only
+// layers that are not shadowed by a personal alias.
+func List(ctx context.Context, q data.Querier, limit int, sortByFreq bool) ([]data.Package, error) {
+	var packages []data.Package
+	var err error
+
+	if sortByFreq {
+		packages, err = q.ListPackagesByFrequency(ctx, int64(limit))
+	} else {
+		packages, err = q.ListPackagesByLastUsed(ctx, int64(limit))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	layers, err := ConfiguredLayers()
+	if err != nil {
+		return nil, err
+	}
+	packages = layers.Merge(packages)
+	if limit >= 0 && len(packages) > limit {
+		packages = packages[:limit]
+	}
+
+	return packages, nil
+}
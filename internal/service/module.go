@@ -0,0 +1,90 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lewvy/gopk/internal/data"
+)
+
+// moduleDepth is the number of path elements that make up the module root
+// on hosts with a predictable layout. Paths on other hosts are assumed to be
+// module roots themselves unless a module is given explicitly.
+var moduleDepth = map[string]int{
+	"github.com":        3,
+	"gitlab.com":        3,
+	"bitbucket.org":     3,
+	"codeberg.org":      3,
+	"golang.org":        3,
+	"go.uber.org":       2,
+	"google.golang.org": 2,
+	"k8s.io":            2,
+	"gorm.io":           2,
+	"go.etcd.io":        2,
+	"honnef.co":         3,
+}
+
+func inferModule(importPath string) string {
+	parts := strings.Split(importPath, "/")
+
+	depth, ok := moduleDepth[parts[0]]
+	if parts[0] == "gopkg.in" {
+		// gopkg.in/yaml.v3 and gopkg.in/user/pkg.v1
+		depth, ok = 3, true
+		if len(parts) > 1 && strings.Contains(parts[1], ".v") {
+			depth = 2
+		}
+	}
+	if !ok || len(parts) <= depth {
+		return importPath
+	}
+
+	if moduleVerRe.MatchString(parts[depth]) {
+		depth++
+	}
+
+	return strings.Join(parts[:depth], "/")
+}
+
+// ModulePath returns the module a package belongs to, falling back to its
+// import path for rows saved before modules were tracked.
+func ModulePath(pkg data.Package) string {
+	if pkg.Module != "" {
+		return pkg.Module
+	}
+	return pkg.Url
+}
+
+// ModuleQuery returns the argument to 'go get' for the module of pkg:
+// module@version for a pinned version, or the bare module path for
+// "latest" and rows saved without a version.
+func ModuleQuery(pkg data.Package) string {
+	mod := ModulePath(pkg)
+	switch v := pkg.Version.String; v {
+	case "", "latest":
+		return mod
+	default:
+		return mod + "@" + v
+	}
+}
+
+// ModuleQueries returns the 'go get' argument for each distinct module of
+// pkgs, in order. When packages of the same module are saved with
+// different versions, the first one wins and the others are reported
+// through Warn.
+func ModuleQueries(pkgs []data.Package) []string {
+	seen := make(map[string]string)
+	var queries []string
+	for _, pkg := range pkgs {
+		mod, query := ModulePath(pkg), ModuleQuery(pkg)
+		if prev, ok := seen[mod]; ok {
+			if prev != query {
+				Warn(fmt.Sprintf("%s: using %s, not %s", pkg.Name, prev, query))
+			}
+			continue
+		}
+		seen[mod] = query
+		queries = append(queries, query)
+	}
+	return queries
+}
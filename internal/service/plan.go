@@ -0,0 +1,568 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/lewvy/gopk/internal/data"
+)
+
+type ChangeKind int
+
+const (
+	ChangeCreate ChangeKind = iota
+	ChangeUpdate
+	ChangeDelete
+	ChangeConflict
+)
+
+func (k ChangeKind) Symbol() string {
+	switch k {
+	case ChangeCreate:
+		return "+"
+	case ChangeUpdate:
+		return "~"
+	case ChangeDelete:
+		return "-"
+	default:
+		return "!"
+	}
+}
+
+// Change is a single step of a Plan. Object is one of "package",
+// "snippet", "group" or "member".
+type Change struct {
+	Kind   ChangeKind
+	Object string
+	Name   string
+	Detail string
+
+	apply func(ctx context.Context, q data.Querier) error
+}
+
+func (c Change) String() string {
+	s := fmt.Sprintf("%s %s %s", c.Kind.Symbol(), c.Object, c.Name)
+	if c.Detail != "" {
+		s += ": " + c.Detail
+	}
+	return s
+}
+
+// Plan is the list of changes needed to bring the registry in line with a
+// RegistryFile.
+type Plan struct {
+	Changes []Change
+}
+
+// Pending returns the number of changes that Apply would make.
+func (p Plan) Pending() int {
+	n := 0
+	for _, c := range p.Changes {
+		if c.Kind != ChangeConflict {
+			n++
+		}
+	}
+	return n
+}
+
+// Summary counts the changes of the plan by kind, in the style of
+// "2 to add, 1 to change, 0 to delete".
+func (p Plan) Summary() string {
+	counts := make(map[ChangeKind]int)
+	for _, c := range p.Changes {
+		counts[c.Kind]++
+	}
+	s := fmt.Sprintf("%d to add, %d to change, %d to delete",
+		counts[ChangeCreate], counts[ChangeUpdate], counts[ChangeDelete])
+	if n := counts[ChangeConflict]; n > 0 {
+		s += fmt.Sprintf(", %d conflicting", n)
+	}
+	return s
+}
+
+func (p Plan) Conflicts() []Change {
+	var conflicts []Change
+	for _, c := range p.Changes {
+		if c.Kind == ChangeConflict {
+			conflicts = append(conflicts, c)
+		}
+	}
+	return conflicts
+}
+
+// Apply makes every change of the plan, in order, skipping conflicts.
+func (p Plan) Apply(ctx context.Context, q data.Querier) error {
+	var errs []error
+	for _, c := range p.Changes {
+		if c.apply == nil {
+			continue
+		}
+		if err := c.apply(ctx, q); err != nil {
+			errs = append(errs, fmt.Errorf("%s %s: %w", c.Object, c.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// PlanImport compares f with the registry. Packages, extra aliases,
+// snippets, groups, memberships and templates missing from the registry
+// are created and differing ones updated, using the same alias rules as
+// Add. With prune, everything that f does not declare is deleted, so the
+// registry ends up matching f. Templates are shared by every profile, so
+// they are only pruned when f has a templates key, even an empty one.
+func PlanImport(ctx context.Context, q data.Querier, f RegistryFile, prune bool) (Plan, error) {
+	var plan Plan
+
+	current, err := q.ListPackagesByLastUsed(ctx, -1)
+	if err != nil {
+		return plan, err
+	}
+	byName := make(map[string]data.Package, len(current))
+	byURL := make(map[string]data.Package, len(current))
+	for _, pkg := range current {
+		byName[pkg.Name] = pkg
+		byURL[pkg.Url] = pkg
+	}
+
+	declared := make(map[string]struct{}, len(f.Packages))
+	for _, fp := range f.Packages {
+		declared[fp.Name] = struct{}{}
+	}
+
+	renamed := make(map[string]struct{})
+	for _, fp := range f.Packages {
+		want := normalizeFilePackage(fp)
+		cur, exists := byName[want.Name]
+		snippetsOf := cur.ID
+
+		if owner, ok := byURL[want.URL]; ok && owner.Name != want.Name {
+			if _, keep := declared[owner.Name]; keep || !prune || exists {
+				plan.Changes = append(plan.Changes, Change{
+					Kind:   ChangeConflict,
+					Object: "package",
+					Name:   want.Name,
+					Detail: fmt.Sprintf("%s is already saved as %q", want.URL, owner.Name),
+				})
+				continue
+			}
+			renamed[owner.Name] = struct{}{}
+			snippetsOf = owner.ID
+			plan.Changes = append(plan.Changes, Change{
+				Kind:   ChangeUpdate,
+				Object: "package",
+				Name:   want.Name,
+				Detail: fmt.Sprintf("renamed from %q", owner.Name),
+				apply:  renamePackage(owner.ID, want),
+			})
+		} else if !exists {
+			plan.Changes = append(plan.Changes, Change{
+				Kind:   ChangeCreate,
+				Object: "package",
+				Name:   want.Name,
+				Detail: want.URL + "@" + want.Version,
+				apply:  addFilePackage(want),
+			})
+		} else if diff := packageDiff(cur, want); diff != "" {
+			plan.Changes = append(plan.Changes, Change{
+				Kind:   ChangeUpdate,
+				Object: "package",
+				Name:   want.Name,
+				Detail: diff,
+				apply:  addFilePackage(want),
+			})
+		}
+
+		var snippets []data.Snippet
+		if snippetsOf != 0 {
+			if snippets, err = q.ListSnippetsByPackage(ctx, snippetsOf); err != nil {
+				return plan, err
+			}
+		}
+		plan.Changes = append(plan.Changes, planSnippets(want, snippets, prune)...)
+
+		var aliases []data.PackageAlias
+		if snippetsOf != 0 {
+			if aliases, err = q.ListAliasesByPackage(ctx, snippetsOf); err != nil {
+				return plan, err
+			}
+		}
+		plan.Changes = append(plan.Changes, planAliases(want, aliases, prune)...)
+	}
+
+	if prune {
+		for _, pkg := range current {
+			_, keep := declared[pkg.Name]
+			_, moved := renamed[pkg.Name]
+			if keep || moved {
+				continue
+			}
+			name := pkg.Name
+			plan.Changes = append(plan.Changes, Change{
+				Kind:   ChangeDelete,
+				Object: "package",
+				Name:   name,
+				Detail: pkg.Url,
+				apply: func(ctx context.Context, q data.Querier) error {
+					_, err := DeletePackage(ctx, q, []string{name})
+					return err
+				},
+			})
+		}
+	}
+
+	groupChanges, err := planGroups(ctx, q, f.Groups, prune)
+	if err != nil {
+		return plan, err
+	}
+	plan.Changes = append(plan.Changes, groupChanges...)
+
+	templateChanges, err := planTemplates(f.Templates, prune && f.Templates != nil)
+	if err != nil {
+		return plan, err
+	}
+	plan.Changes = append(plan.Changes, templateChanges...)
+
+	return plan, nil
+}
+
+func normalizeFilePackage(fp FilePackage) FilePackage {
+	fp.URL = normalizeURL(fp.URL)
+	fp.Module = normalizeURL(fp.Module)
+	if fp.Module == "" {
+		fp.Module = inferModule(fp.URL)
+	}
+	if fp.Version == "" {
+		fp.Version = "latest"
+	}
+	if fp.Kind == "" {
+		fp.Kind = KindLibrary
+	}
+	return fp
+}
+
+func packageDiff(cur data.Package, want FilePackage) string {
+	var diffs []string
+	field := func(name, from, to string) {
+		if from != to {
+			diffs = append(diffs, fmt.Sprintf("%s %q -> %q", name, from, to))
+		}
+	}
+	field("url", cur.Url, want.URL)
+	field("module", ModulePath(cur), want.Module)
+	field("import name", cur.ImportName, want.ImportName)
+	field("version", cur.Version.String, want.Version)
+	field("kind", KindOf(cur), want.Kind)
+	return strings.Join(diffs, ", ")
+}
+
+func addFilePackage(fp FilePackage) func(context.Context, data.Querier) error {
+	return func(ctx context.Context, q data.Querier) error {
+		// The file declares the import path of the alias, so an update
+		// moves the alias like --force.
+		_, err := Add(ctx, q, AddParams{
+			URL:        fp.URL,
+			Module:     fp.Module,
+			Name:       fp.Name,
+			ImportName: fp.ImportName,
+			Version:    fp.Version,
+			Kind:       fp.Kind,
+			Force:      true,
+		})
+		return err
+	}
+}
+
+func renamePackage(id int64, fp FilePackage) func(context.Context, data.Querier) error {
+	return func(ctx context.Context, q data.Querier) error {
+		_, err := q.UpdatePackage(ctx, data.UpdatePackageParams{
+			ID:         id,
+			Name:       fp.Name,
+			Url:        fp.URL,
+			Module:     fp.Module,
+			ImportName: fp.ImportName,
+			Version:    sql.NullString{Valid: true, String: fp.Version},
+			Kind:       fp.Kind,
+		})
+		return err
+	}
+}
+
+func planSnippets(fp FilePackage, current []data.Snippet, prune bool) []Change {
+	var changes []Change
+
+	have := make(map[string]string, len(current))
+	for _, s := range current {
+		have[s.Name] = s.Body
+	}
+
+	declared := make(map[string]struct{}, len(fp.Snippets))
+	for _, s := range fp.Snippets {
+		declared[s.Name] = struct{}{}
+		body, exists := have[s.Name]
+		if exists && body == strings.TrimSpace(s.Body) {
+			continue
+		}
+
+		kind := ChangeCreate
+		if exists {
+			kind = ChangeUpdate
+		}
+		alias, name, text := fp.Name, s.Name, s.Body
+		changes = append(changes, Change{
+			Kind:   kind,
+			Object: "snippet",
+			Name:   alias + "/" + name,
+			apply: func(ctx context.Context, q data.Querier) error {
+				return AddSnippet(ctx, q, alias, name, text)
+			},
+		})
+	}
+
+	if prune {
+		for _, s := range current {
+			if _, keep := declared[s.Name]; keep {
+				continue
+			}
+			alias, name := fp.Name, s.Name
+			changes = append(changes, Change{
+				Kind:   ChangeDelete,
+				Object: "snippet",
+				Name:   alias + "/" + name,
+				apply: func(ctx context.Context, q data.Querier) error {
+					return DeleteSnippet(ctx, q, alias, name)
+				},
+			})
+		}
+	}
+
+	return changes
+}
+
+func planAliases(fp FilePackage, current []data.PackageAlias, prune bool) []Change {
+	var changes []Change
+
+	have := make(map[string]struct{}, len(current))
+	for _, a := range current {
+		have[a.Name] = struct{}{}
+	}
+
+	declared := make(map[string]struct{}, len(fp.Aliases))
+	for _, alias := range fp.Aliases {
+		declared[alias] = struct{}{}
+		if _, exists := have[alias]; exists {
+			continue
+		}
+		name, alias := fp.Name, alias
+		changes = append(changes, Change{
+			Kind:   ChangeCreate,
+			Object: "alias",
+			Name:   alias,
+			Detail: "of " + name,
+			apply: func(ctx context.Context, q data.Querier) error {
+				_, err := AddAliases(ctx, q, name, []string{alias})
+				return err
+			},
+		})
+	}
+
+	if prune {
+		for _, a := range current {
+			if _, keep := declared[a.Name]; keep {
+				continue
+			}
+			alias := a.Name
+			changes = append(changes, Change{
+				Kind:   ChangeDelete,
+				Object: "alias",
+				Name:   alias,
+				Detail: "of " + fp.Name,
+				apply: func(ctx context.Context, q data.Querier) error {
+					return RemoveAliases(ctx, q, []string{alias})
+				},
+			})
+		}
+	}
+
+	return changes
+}
+
+func planGroups(ctx context.Context, q data.Querier, groups []FileGroup, prune bool) ([]Change, error) {
+	var changes []Change
+
+	current, err := q.ListGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	exists := make(map[string]struct{}, len(current))
+	for _, g := range current {
+		exists[g.Name] = struct{}{}
+	}
+
+	declared := make(map[string]struct{}, len(groups))
+	for _, fg := range groups {
+		declared[fg.Name] = struct{}{}
+
+		var pkgs []data.Package
+		members := make(map[string]struct{})
+		if _, ok := exists[fg.Name]; ok {
+			pkgs, err = q.ListPackagesByGroup(ctx, fg.Name)
+			if err != nil {
+				return nil, err
+			}
+			for _, pkg := range pkgs {
+				members[pkg.Name] = struct{}{}
+			}
+		} else {
+			group := fg.Name
+			changes = append(changes, Change{
+				Kind:   ChangeCreate,
+				Object: "group",
+				Name:   group,
+				apply: func(ctx context.Context, q data.Querier) error {
+					_, err := q.CreateGroup(ctx, group)
+					return err
+				},
+			})
+		}
+
+		wanted := make(map[string]struct{}, len(fg.Packages))
+		for _, name := range fg.Packages {
+			wanted[name] = struct{}{}
+			if _, ok := members[name]; ok {
+				continue
+			}
+			group, alias := fg.Name, name
+			changes = append(changes, Change{
+				Kind:   ChangeCreate,
+				Object: "member",
+				Name:   group + "/" + alias,
+				apply: func(ctx context.Context, q data.Querier) error {
+					groupID, err := q.GetGroupIDByName(ctx, group)
+					if err != nil {
+						return err
+					}
+					pkgID, err := q.GetIDByName(ctx, alias)
+					if err != nil {
+						return err
+					}
+					return q.AssignPackageToGroup(ctx, data.AssignPackageToGroupParams{GroupID: groupID, PackageID: pkgID})
+				},
+			})
+		}
+
+		if prune {
+			for _, pkg := range pkgs {
+				if _, keep := wanted[pkg.Name]; keep {
+					continue
+				}
+				group, id := fg.Name, pkg.ID
+				changes = append(changes, Change{
+					Kind:   ChangeDelete,
+					Object: "member",
+					Name:   group + "/" + pkg.Name,
+					apply: func(ctx context.Context, q data.Querier) error {
+						groupID, err := q.GetGroupIDByName(ctx, group)
+						if err != nil {
+							return err
+						}
+						return q.RemovePackagesFromGroup(ctx, data.RemovePackagesFromGroupParams{GroupID: groupID, PackageIds: []int64{id}})
+					},
+				})
+			}
+		}
+	}
+
+	if prune {
+		for _, g := range current {
+			if _, keep := declared[g.Name]; keep {
+				continue
+			}
+			group := g
+			changes = append(changes, Change{
+				Kind:   ChangeDelete,
+				Object: "group",
+				Name:   group.Name,
+				apply: func(ctx context.Context, q data.Querier) error {
+					return DeleteGroup(ctx, q, group)
+				},
+			})
+		}
+	}
+
+	return changes, nil
+}
+
+// planTemplates compares templates with the saved ones. Templates live in
+// the config directory rather than the registry, so their changes ignore
+// the Querier they are applied with.
+func planTemplates(templates []FileTemplate, prune bool) ([]Change, error) {
+	var changes []Change
+
+	current, err := ListTemplates()
+	if err != nil {
+		return nil, err
+	}
+	have := make(map[string]FileTemplate, len(current))
+	for _, t := range current {
+		have[t.Name] = t.FileTemplate()
+	}
+
+	declared := make(map[string]struct{}, len(templates))
+	for _, ft := range templates {
+		declared[ft.Name] = struct{}{}
+		cur, exists := have[ft.Name]
+		if exists && sameTemplate(cur, ft) {
+			continue
+		}
+
+		kind := ChangeCreate
+		if exists {
+			kind = ChangeUpdate
+		}
+		t := ft.template()
+		changes = append(changes, Change{
+			Kind:   kind,
+			Object: "template",
+			Name:   ft.Name,
+			Detail: fmt.Sprintf("%d files", len(ft.Files)),
+			apply: func(context.Context, data.Querier) error {
+				return SaveTemplate(t)
+			},
+		})
+	}
+
+	if prune {
+		for _, t := range current {
+			if _, keep := declared[t.Name]; keep {
+				continue
+			}
+			name := t.Name
+			changes = append(changes, Change{
+				Kind:   ChangeDelete,
+				Object: "template",
+				Name:   name,
+				apply: func(context.Context, data.Querier) error {
+					return DeleteTemplate(name)
+				},
+			})
+		}
+	}
+
+	return changes, nil
+}
+
+func sameTemplate(a, b FileTemplate) bool {
+	sortFiles := func(files []FileTemplateFile) []FileTemplateFile {
+		files = slices.Clone(files)
+		slices.SortFunc(files, func(x, y FileTemplateFile) int { return strings.Compare(x.Path, y.Path) })
+		return files
+	}
+	return a.Description == b.Description &&
+		slices.Equal(a.Packages, b.Packages) &&
+		slices.Equal(a.Commands, b.Commands) &&
+		slices.Equal(sortFiles(a.Files), sortFiles(b.Files))
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lewvy/gopk/internal/data"
+	"github.com/lewvy/gopk/internal/gotool"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+// SetReplace records the replacement used for the package saved under
+// name, a primary or extra alias: a local directory, which is stored as an
+// absolute path, or a fork module query such as github.com/me/zap@v1.27.0.
+// An empty target clears it. With always, every install of the package
+// applies the replacement; otherwise only installs with InstallOptions.Local
+// do.
+func SetReplace(ctx context.Context, q data.Querier, name, target string, always bool) (data.Package, error) {
+	pkg, err := getPackage(ctx, q, name)
+	if err != nil {
+		return data.Package{}, err
+	}
+
+	if target != "" {
+		target, err = normalizeReplace(target)
+		if err != nil {
+			return data.Package{}, err
+		}
+		checkReplaceDir(ModulePath(pkg), target)
+	} else {
+		always = false
+	}
+
+	return q.SetPackageReplace(ctx, data.SetPackageReplaceParams{
+		Name:          pkg.Name,
+		ReplaceWith:   target,
+		ReplaceAlways: always,
+	})
+}
+
+// isLocalReplace reports whether target is a directory rather than a
+// module, using the same rule as go.mod.
+func isLocalReplace(target string) bool {
+	return filepath.IsAbs(target) || target == "." || target == ".." || target == "~" ||
+		strings.HasPrefix(target, "./") || strings.HasPrefix(target, "../") || strings.HasPrefix(target, "~/")
+}
+
+func normalizeReplace(target string) (string, error) {
+	if !isLocalReplace(target) {
+		path, version := gotool.Version(target)
+		if version == "" {
+			return "", fmt.Errorf("fork %s needs a version, such as %s@v1.2.3", target, target)
+		}
+		if err := module.CheckPath(path); err != nil {
+			return "", err
+		}
+		return target, nil
+	}
+
+	if target == "~" || strings.HasPrefix(target, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		target = filepath.Join(home, strings.TrimPrefix(target, "~"))
+	}
+	return filepath.Abs(target)
+}
+
+// checkReplaceDir warns when a local replacement is not a checkout of mod.
+func checkReplaceDir(mod, target string) {
+	if !isLocalReplace(target) {
+		return
+	}
+
+	content, err := os.ReadFile(filepath.Join(target, "go.mod"))
+	if errors.Is(err, os.ErrNotExist) {
+		Warn(fmt.Sprintf("%s has no go.mod; the go command will refuse to use it as a replacement", target))
+		return
+	}
+	if err != nil {
+		Warn(fmt.Sprintf("reading %s: %v", target, err))
+		return
+	}
+	if path := modfile.ModulePath(content); path != mod {
+		Warn(fmt.Sprintf("%s declares module %s, not %s", target, path, mod))
+	}
+}
+
+// replaced returns the packages of pkgs whose replacement applies.
+func replaced(pkgs []data.Package, local bool) []data.Package {
+	var out []data.Package
+	for _, pkg := range pkgs {
+		if pkg.ReplaceWith != "" && (local || pkg.ReplaceAlways) {
+			out = append(out, pkg)
+		}
+	}
+	return out
+}
+
+// addReplaces adds a replace directive for the module of each package to
+// the go.mod of the module in dir, pointing at its saved replacement.
+func addReplaces(ctx context.Context, dir string, pkgs []data.Package) error {
+	if len(pkgs) == 0 {
+		return nil
+	}
+
+	var args []string
+	for _, pkg := range pkgs {
+		if pkg.ReplaceWith == "" {
+			return fmt.Errorf("%s has no replacement; set one with 'gopk fork %s <dir|module@version>'", pkg.Name, pkg.Name)
+		}
+		checkReplaceDir(ModulePath(pkg), pkg.ReplaceWith)
+		args = append(args, "-replace="+ModulePath(pkg)+"="+pkg.ReplaceWith)
+	}
+
+	runner, err := goRunner(dir)
+	if err != nil {
+		return err
+	}
+	if err := runner.ModEdit(ctx, args...); err != nil {
+		return fmt.Errorf("replace failed: %w", err)
+	}
+	return nil
+}
+
+// Unreplace drops the replace directives for the modules of the packages
+// saved under names from the go.mod of the current module, then runs
+// 'go get' for them so that go.mod and go.sum point at the upstream module
+// again, at the saved version.
+func Unreplace(ctx context.Context, q data.Querier, names []string) error {
+	pkgs, missing, err := Resolve(ctx, q, names)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrNotFound, strings.Join(missing, ", "))
+	}
+	if _, err := findGoMod("."); err != nil {
+		return err
+	}
+
+	var args []string
+	for _, pkg := range pkgs {
+		args = append(args, "-dropreplace="+ModulePath(pkg))
+	}
+
+	runner, err := goRunner("")
+	if err != nil {
+		return err
+	}
+	if err := runner.ModEdit(ctx, args...); err != nil {
+		return fmt.Errorf("unreplace failed: %w", err)
+	}
+	return GetFromUrl(ctx, ModuleQueries(pkgs))
+}
@@ -0,0 +1,31 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lewvy/gopk/internal/gotool"
+	"github.com/lewvy/gopk/internal/service"
+)
+
+func TestSetReplaceExtraAlias(t *testing.T) {
+	q := newRegistry(t, &gotool.Fake{})
+	ctx := context.Background()
+	add(t, q, service.AddParams{URL: "go.uber.org/zap"})
+	if _, err := service.AddAliases(ctx, q, "zap", []string{"log"}); err != nil {
+		t.Fatal(err)
+	}
+
+	pkg, err := service.SetReplace(ctx, q, "log", "github.com/me/zap@v1.27.0", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pkg.Name != "zap" || pkg.ReplaceWith != "github.com/me/zap@v1.27.0" {
+		t.Errorf("SetReplace(log): got %s replaced with %q", pkg.Name, pkg.ReplaceWith)
+	}
+
+	if _, err := service.SetReplace(ctx, q, "missing", "", false); !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("SetReplace(missing): got error %v, want ErrNotFound", err)
+	}
+}
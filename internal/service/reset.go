@@ -0,0 +1,10 @@
+package service
+
+import (
+	"github.com/lewvy/gopk/internal/store"
+)
+
+func Reset() error {
+	return store.Reset()
+
+}
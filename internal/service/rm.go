@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"slices"
+
+	"github.com/lewvy/gopk/internal/data"
+)
+
+// DeletePackage marks the packages saved under names, primary or extra
+// aliases, as deleted and returns how many were. Packages that are found
+// are deleted even if some names are missing; the returned error then
+// wraps ErrNotFound and suggests the closest aliases.
+func DeletePackage(ctx context.Context, queries data.Querier, names []string) (int, error) {
+	var primary, missing []string
+	for _, name := range names {
+		pkg, found, err := AliasOwner(ctx, queries, name)
+		if err != nil {
+			return 0, err
+		}
+		if !found {
+			missing = append(missing, name)
+			continue
+		}
+		if !slices.Contains(primary, pkg.Name) {
+			primary = append(primary, pkg.Name)
+		}
+	}
+
+	if len(primary) > 0 {
+		if err := queries.MarkDeleteByName(ctx, primary); err != nil {
+			return 0, err
+		}
+	}
+
+	if len(missing) > 0 {
+		return len(primary), notFound(ctx, queries, missing)
+	}
+
+	return len(primary), nil
+}
+
+func DeleteGroup(ctx context.Context, queries data.Querier, group data.Group) error {
+	return queries.DeleteGroup(ctx, group.Name)
+
+}
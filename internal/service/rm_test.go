@@ -0,0 +1,37 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lewvy/gopk/internal/gotool"
+	"github.com/lewvy/gopk/internal/service"
+)
+
+func TestDeletePackage(t *testing.T) {
+	q := newRegistry(t, &gotool.Fake{})
+	ctx := context.Background()
+	add(t, q, service.AddParams{URL: "go.uber.org/zap"})
+	add(t, q, service.AddParams{URL: "github.com/go-chi/chi/v5"})
+	add(t, q, service.AddParams{URL: "github.com/spf13/cobra"})
+	if _, err := service.AddAliases(ctx, q, "zap", []string{"log"}); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := service.DeletePackage(ctx, q, []string{"log", "zap", "chi", "missing"})
+	if !errors.Is(err, service.ErrNotFound) {
+		t.Errorf("got error %v, want ErrNotFound", err)
+	}
+	if n != 2 {
+		t.Errorf("deleted %d packages, want 2", n)
+	}
+
+	found, missing, err := service.Resolve(ctx, q, []string{"log", "zap", "chi", "cobra"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].Name != "cobra" || len(missing) != 3 {
+		t.Errorf("Resolve after DeletePackage: got %v, missing %v, want only cobra", found, missing)
+	}
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lewvy/gopk/internal/data"
+	"github.com/sahilm/fuzzy"
+)
+
+// searchSource matches the alias and URL of packages together, as the TUI
+// search does, along with their extra aliases.
+type searchSource struct {
+	pkgs    []data.Package
+	aliases map[int64][]string
+}
+
+func (s searchSource) String(i int) string {
+	pkg := s.pkgs[i]
+	if extra := s.aliases[pkg.ID]; len(extra) > 0 {
+		return pkg.Name + " " + strings.Join(extra, " ") + " " + pkg.Url
+	}
+	return pkg.Name + " " + pkg.Url
+}
+
+func (s searchSource) Len() int { return len(s.pkgs) }
+
+// Search returns the saved packages matching query, best match first. The
+// aliases and URL are matched fuzzily, as in the TUI; packages whose
+// synopsis recorded by 'gopk enrich' contains every word of query follow.
+func Search(ctx context.Context, q data.Querier, query string) ([]data.Package, error) {
+	pkgs, err := CompleteAliases(ctx, q, "")
+	if err != nil {
+		return nil, err
+	}
+	info, err := PackageInfo(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	aliases, err := AliasesByPackage(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	return matchPackages(query, searchSource{pkgs, aliases}, info), nil
+}
+
+func matchPackages(query string, src searchSource, info map[int64]data.PackageInfo) []data.Package {
+	pkgs := src.pkgs
+	var out []data.Package
+	matched := make(map[int]bool)
+	for _, m := range fuzzy.FindFrom(query, src) {
+		out = append(out, pkgs[m.Index])
+		matched[m.Index] = true
+	}
+
+	words := strings.Fields(strings.ToLower(query))
+	for i, pkg := range pkgs {
+		synopsis := strings.ToLower(info[pkg.ID].Synopsis)
+		if matched[i] || synopsis == "" || len(words) == 0 {
+			continue
+		}
+		all := true
+		for _, w := range words {
+			all = all && strings.Contains(synopsis, w)
+		}
+		if all {
+			out = append(out, pkg)
+		}
+	}
+	return out
+}
+
+// Suggest returns up to three saved aliases close to name, for "did you
+// mean" hints: aliases within a small edit distance of name, closest
+// first, or else the best fuzzy matches. Extra aliases are suggested
+// along with primary ones.
+func Suggest(ctx context.Context, q data.Querier, name string) ([]string, error) {
+	pkgs, err := CompleteAliases(ctx, q, "")
+	if err != nil {
+		return nil, err
+	}
+	aliases, err := AliasesByPackage(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	type candidate struct {
+		name string
+		dist int
+	}
+	var near []candidate
+	limit := max(1, len(name)/3)
+	for _, pkg := range pkgs {
+		for _, alias := range append([]string{pkg.Name}, aliases[pkg.ID]...) {
+			if d := editDistance(name, alias); d <= limit {
+				near = append(near, candidate{alias, d})
+			}
+		}
+	}
+	// Candidates are in usage order, which breaks ties.
+	sort.SliceStable(near, func(i, j int) bool { return near[i].dist < near[j].dist })
+
+	var out []string
+	for _, c := range near {
+		out = append(out, c.name)
+	}
+	if len(out) == 0 {
+		for _, m := range fuzzy.FindFrom(name, searchSource{pkgs: pkgs}) {
+			out = append(out, pkgs[m.Index].Name)
+		}
+	}
+	if len(out) > 3 {
+		out = out[:3]
+	}
+	return out, nil
+}
+
+// editDistance returns the optimal string alignment distance between a and
+// b: the number of insertions, deletions, substitutions and transpositions
+// of adjacent characters that turn a into b.
+func editDistance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	d := make([][]int, len(s)+1)
+	for i := range d {
+		d[i] = make([]int, len(t)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(s)][len(t)]
+}
+
+// notFound returns an error wrapping ErrNotFound for the missing names,
+// with the closest saved aliases as hints.
+func notFound(ctx context.Context, q data.Querier, missing []string) error {
+	var hints []string
+	for _, name := range missing {
+		if strings.HasPrefix(name, "@") {
+			continue
+		}
+		suggestions, err := Suggest(ctx, q, name)
+		if err == nil && len(suggestions) > 0 {
+			hints = append(hints, fmt.Sprintf("%s: did you mean %s?", name, strings.Join(suggestions, ", ")))
+		}
+	}
+
+	err := fmt.Errorf("%w: %s", ErrNotFound, strings.Join(missing, ", "))
+	if len(hints) > 0 {
+		err = fmt.Errorf("%w\n%s", err, strings.Join(hints, "\n"))
+	}
+	return err
+}
+
+// resolveFuzzy resolves each missing name that fuzzy matches exactly one
+// saved package, and returns the packages found and the names still
+// missing.
+func resolveFuzzy(ctx context.Context, q data.Querier, missing []string) ([]data.Package, []string, error) {
+	var found []data.Package
+	var still []string
+	for _, name := range missing {
+		if strings.HasPrefix(name, "@") {
+			still = append(still, name)
+			continue
+		}
+		matches, err := Search(ctx, q, name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(matches) != 1 {
+			still = append(still, name)
+			continue
+		}
+		Warn(fmt.Sprintf("%s: using %s (%s)", name, matches[0].Name, matches[0].Url))
+		found = append(found, matches[0])
+	}
+	return found, still, nil
+}
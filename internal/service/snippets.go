@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"strings"
+
+	"github.com/lewvy/gopk/internal/data"
+)
+
+var ErrSnippetNotFound = errors.New("snippet not found")
+
+// AddSnippet stores body under name for the package saved as alias,
+// replacing an existing snippet of the same name. The body must parse as
+// Go, either as a list of statements or as top-level declarations.
+func AddSnippet(ctx context.Context, q data.Querier, alias, name, body string) error {
+	pkg, err := getPackage(ctx, q, alias)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return errors.New("snippet name is required")
+	}
+	body = strings.TrimSpace(body)
+	if err := ValidateSnippet(body); err != nil {
+		return fmt.Errorf("invalid snippet: %w", err)
+	}
+
+	_, err = q.UpsertSnippet(ctx, data.UpsertSnippetParams{
+		PackageID: pkg.ID,
+		Name:      name,
+		Body:      body,
+	})
+	return err
+}
+
+func ListSnippets(ctx context.Context, q data.Querier, alias string) ([]data.Snippet, error) {
+	pkg, err := getPackage(ctx, q, alias)
+	if err != nil {
+		return nil, err
+	}
+	return q.ListSnippetsByPackage(ctx, pkg.ID)
+}
+
+func GetSnippet(ctx context.Context, q data.Querier, alias, name string) (data.Snippet, error) {
+	pkg, err := getPackage(ctx, q, alias)
+	if err != nil {
+		return data.Snippet{}, err
+	}
+	snippet, err := q.GetSnippet(ctx, data.GetSnippetParams{PackageID: pkg.ID, Name: name})
+	if errors.Is(err, sql.ErrNoRows) {
+		return data.Snippet{}, ErrSnippetNotFound
+	}
+	return snippet, err
+}
+
+func DeleteSnippet(ctx context.Context, q data.Querier, alias, name string) error {
+	pkg, err := getPackage(ctx, q, alias)
+	if err != nil {
+		return err
+	}
+	n, err := q.DeleteSnippet(ctx, data.DeleteSnippetParams{PackageID: pkg.ID, Name: name})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrSnippetNotFound
+	}
+	return nil
+}
+
+// ValidateSnippet reports whether body is syntactically valid Go. Snippets
+// are usually a few statements, so they are parsed inside a function body
+// when they do not form a valid file on their own.
+func ValidateSnippet(body string) error {
+	fset := token.NewFileSet()
+	if _, err := parser.ParseFile(fset, "", "package p\n"+body, parser.SkipObjectResolution); err == nil {
+		return nil
+	}
+	_, err := parser.ParseFile(fset, "snippet.go", "package p\nfunc _() {\n"+body+"\n}\n", parser.SkipObjectResolution)
+	return err
+}
+
+// getPackage returns the live package saved as alias, which may be a
+// primary or an extra alias.
+func getPackage(ctx context.Context, q data.Querier, alias string) (data.Package, error) {
+	pkg, found, err := AliasOwner(ctx, q, alias)
+	if err != nil {
+		return data.Package{}, err
+	}
+	if !found {
+		return data.Package{}, fmt.Errorf("%w: %s", ErrNotFound, alias)
+	}
+	return pkg, nil
+}
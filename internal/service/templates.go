@@ -0,0 +1,391 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/lewvy/gopk/config"
+	"github.com/lewvy/gopk/internal/data"
+	"github.com/pelletier/go-toml/v2"
+)
+
+var ErrTemplateNotFound = errors.New("template not found")
+
+var templateNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// templateManifest is the name of the file describing a template in its
+// directory, next to the files directory.
+const templateManifest = "template.toml"
+
+// Template is a named project skeleton. Each template is stored in its own
+// directory under config.TemplatesDir: a template.toml manifest and a
+// files directory whose contents are rendered into new projects.
+type Template struct {
+	Name        string   `toml:"-"`
+	Description string   `toml:"description,omitempty"`
+	Packages    []string `toml:"packages,omitempty"`
+	Commands    []string `toml:"commands,omitempty"`
+
+	Files []TemplateFile `toml:"-"`
+}
+
+// TemplateFile is a file of a template. Both its slash-separated path and
+// its body are text/template sources executed with a ProjectData; a
+// trailing .tmpl is dropped from the rendered path.
+type TemplateFile struct {
+	Path string
+	Body string
+}
+
+func validateTemplate(t Template) error {
+	if !templateNameRe.MatchString(t.Name) {
+		return fmt.Errorf("invalid template name %q: use letters, digits, '.', '-' and '_'", t.Name)
+	}
+	if len(t.Files) == 0 {
+		return fmt.Errorf("template %s has no files", t.Name)
+	}
+	seen := make(map[string]bool, len(t.Files))
+	for _, f := range t.Files {
+		if !filepath.IsLocal(filepath.FromSlash(f.Path)) {
+			return fmt.Errorf("template %s: file path %q must be relative and stay inside the project", t.Name, f.Path)
+		}
+		if seen[f.Path] {
+			return fmt.Errorf("template %s: file %s is declared twice", t.Name, f.Path)
+		}
+		seen[f.Path] = true
+	}
+	return nil
+}
+
+// ListTemplates returns the saved templates, sorted by name.
+func ListTemplates() ([]Template, error) {
+	dir, err := config.TemplatesDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var templates []Template
+	for _, e := range entries {
+		if !e.IsDir() || !templateNameRe.MatchString(e.Name()) {
+			continue
+		}
+		t, err := LoadTemplate(e.Name())
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+	return templates, nil
+}
+
+// LoadTemplate reads the template saved under name.
+func LoadTemplate(name string) (Template, error) {
+	if !templateNameRe.MatchString(name) {
+		return Template{}, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+	root, err := config.TemplatesDir()
+	if err != nil {
+		return Template{}, err
+	}
+	dir := filepath.Join(root, name)
+
+	t := Template{Name: name}
+	content, err := os.ReadFile(filepath.Join(dir, templateManifest))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		if _, err := os.Stat(dir); err != nil {
+			return Template{}, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+		}
+	case err != nil:
+		return Template{}, err
+	default:
+		if err := toml.Unmarshal(content, &t); err != nil {
+			return Template{}, fmt.Errorf("invalid template %s: %w", name, err)
+		}
+	}
+
+	files := filepath.Join(dir, "files")
+	err = filepath.WalkDir(files, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		body, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(files, path)
+		if err != nil {
+			return err
+		}
+		t.Files = append(t.Files, TemplateFile{Path: filepath.ToSlash(rel), Body: string(body)})
+		return nil
+	})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return Template{}, err
+	}
+	return t, nil
+}
+
+// SaveTemplate writes t to the templates directory, replacing the template
+// of the same name.
+func SaveTemplate(t Template) error {
+	if err := validateTemplate(t); err != nil {
+		return err
+	}
+	root, err := config.TemplatesDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return err
+	}
+
+	// Build the new template next to the old one and swap them, so that a
+	// failed write keeps the old template.
+	tmp, err := os.MkdirTemp(root, "."+t.Name+"-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	manifest, err := toml.Marshal(t)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(tmp, templateManifest), manifest, 0644); err != nil {
+		return err
+	}
+	for _, f := range t.Files {
+		path := filepath.Join(tmp, "files", filepath.FromSlash(f.Path))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(f.Body), 0644); err != nil {
+			return err
+		}
+	}
+
+	dir := filepath.Join(root, t.Name)
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	return os.Rename(tmp, dir)
+}
+
+// DeleteTemplate removes the template saved under name.
+func DeleteTemplate(name string) error {
+	if _, err := LoadTemplate(name); err != nil {
+		return err
+	}
+	root, err := config.TemplatesDir()
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(filepath.Join(root, name))
+}
+
+// ReadTemplateDir reads every file below dir as a template file, skipping
+// version control directories.
+func ReadTemplateDir(dir string) ([]TemplateFile, error) {
+	var files []TemplateFile
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && (d.Name() == ".git" || d.Name() == ".hg" || d.Name() == ".svn") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		body, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, TemplateFile{Path: filepath.ToSlash(rel), Body: string(body)})
+		return nil
+	})
+	return files, err
+}
+
+// Render executes the paths and bodies of the files of t with d. Go files
+// get their standard library imports fixed and are formatted.
+func (t Template) Render(d ProjectData) (map[string][]byte, error) {
+	out := make(map[string][]byte, len(t.Files))
+	for _, f := range t.Files {
+		rendered, err := execute(t.Name+":"+f.Path, f.Path, d)
+		if err != nil {
+			return nil, err
+		}
+		path := strings.TrimSuffix(string(rendered), ".tmpl")
+		if !filepath.IsLocal(filepath.FromSlash(path)) {
+			return nil, fmt.Errorf("template %s: %s renders to %q, outside the project", t.Name, f.Path, path)
+		}
+
+		body, err := execute(t.Name+":"+f.Path, f.Body, d)
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasSuffix(path, ".go") {
+			if body, err = fixImports(path, body); err != nil {
+				return nil, err
+			}
+		}
+		out[path] = body
+	}
+	return out, nil
+}
+
+func execute(name, text string, d ProjectData) ([]byte, error) {
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, d); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// NewProjectOptions control NewProject.
+type NewProjectOptions struct {
+	// Group is installed along with the packages of the template, which
+	// can refer to its members like to its own packages.
+	Group string
+
+	// SkipCommands does not run the commands of the template.
+	SkipCommands bool
+
+	// Output receives the output of the commands. When nil, it is only
+	// reported if a command fails.
+	Output io.Writer
+
+	InstallOptions
+}
+
+// NewProject creates a module with the given path in dir from the template
+// saved under name: it runs 'go mod init', writes the rendered files,
+// installs the template's packages, and those of opts.Group, at their
+// saved versions and then runs its commands in dir.
+func NewProject(ctx context.Context, q data.Querier, name, dir, modulePath string, opts NewProjectOptions) error {
+	t, err := LoadTemplate(name)
+	if err != nil {
+		return err
+	}
+	if err := checkNewModuleDir(dir); err != nil {
+		return err
+	}
+
+	pkgs, missing, err := Resolve(ctx, q, t.Packages)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("template %s: %w: %s", name, ErrNotFound, strings.Join(missing, ", "))
+	}
+	if opts.Group != "" {
+		members, err := ListPackagesByGroupOrderByFreq(ctx, q, opts.Group)
+		if err != nil {
+			return err
+		}
+		if len(members) == 0 {
+			return fmt.Errorf("group %s has no packages", opts.Group)
+		}
+		for _, pkg := range members {
+			if !slices.ContainsFunc(pkgs, func(p data.Package) bool { return p.ID == pkg.ID }) {
+				pkgs = append(pkgs, pkg)
+			}
+		}
+	}
+
+	d, err := NewProjectData(ctx, q, modulePath, pkgs)
+	if err != nil {
+		return err
+	}
+	files, err := t.Render(d)
+	if err != nil {
+		return err
+	}
+	var commands []string
+	for _, c := range t.Commands {
+		c, err := execute(name+":command", c, d)
+		if err != nil {
+			return err
+		}
+		commands = append(commands, string(c))
+	}
+
+	if err := createModule(ctx, q, dir, modulePath, files, pkgs, opts.InstallOptions); err != nil {
+		return err
+	}
+	if opts.SkipCommands {
+		return nil
+	}
+	for _, c := range commands {
+		if err := runCommand(ctx, dir, c, opts.Output); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runCommand runs a template command with the system shell in dir.
+func runCommand(ctx context.Context, dir, command string, w io.Writer) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Dir = dir
+
+	var buf bytes.Buffer
+	if w == nil {
+		w = &buf
+	}
+	cmd.Stdout = w
+	cmd.Stderr = w
+
+	if err := cmd.Run(); err != nil {
+		if out := strings.TrimSpace(buf.String()); out != "" {
+			return fmt.Errorf("%s: %w\n%s", command, err, out)
+		}
+		return fmt.Errorf("%s: %w", command, err)
+	}
+	return nil
+}
+
+// sortedPaths returns the paths of files in order.
+func sortedPaths(files map[string][]byte) []string {
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
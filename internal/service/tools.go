@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"debug/buildinfo"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/lewvy/gopk/internal/data"
+)
+
+// ToolState describes how an installed binary compares with the registry.
+type ToolState int
+
+const (
+	// ToolInstalled means the binary is at the saved version, or at any
+	// version for tools saved as latest.
+	ToolInstalled ToolState = iota
+	ToolMissing
+	ToolDrifted
+)
+
+func (s ToolState) String() string {
+	switch s {
+	case ToolInstalled:
+		return "installed"
+	case ToolMissing:
+		return "missing"
+	default:
+		return "drifted"
+	}
+}
+
+// ToolStatus is a saved tool along with the binary installed for it.
+type ToolStatus struct {
+	Package data.Package
+	// Binary is the path of the installed binary; it is empty when the
+	// tool is missing.
+	Binary string
+	// Installed is the module version the binary was built from.
+	Installed string
+	State     ToolState
+}
+
+// Binary is a Go binary found in the install directory.
+type Binary struct {
+	File string
+	// Package is the import path of the main package.
+	Package string
+	Version string
+}
+
+// BinDir returns the directory 'go install' writes binaries to: GOBIN, or
+// the bin directory of the first GOPATH entry.
+func BinDir(ctx context.Context) (string, error) {
+	runner, err := goRunner("")
+	if err != nil {
+		return "", err
+	}
+	env, err := runner.Env(ctx, "GOBIN", "GOPATH")
+	if err != nil {
+		return "", err
+	}
+	if env[0] != "" {
+		return env[0], nil
+	}
+	gopath := filepath.SplitList(env[1])
+	if len(gopath) == 0 || gopath[0] == "" {
+		return "", errors.New("neither GOBIN nor GOPATH is set")
+	}
+	return filepath.Join(gopath[0], "bin"), nil
+}
+
+// InstalledBinaries reads the build info of the Go binaries in dir and
+// returns them keyed by main package path. Files that are not Go binaries
+// are skipped, and a missing dir holds no binaries.
+func InstalledBinaries(dir string) (map[string]Binary, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]Binary{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	bins := make(map[string]Binary, len(entries))
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		file := filepath.Join(dir, e.Name())
+		info, err := buildinfo.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		bins[info.Path] = Binary{File: file, Package: info.Path, Version: info.Main.Version}
+	}
+	return bins, nil
+}
+
+// Tools returns the saved tools, sorted by alias, with the binaries
+// installed for them in BinDir.
+func Tools(ctx context.Context, q data.Querier) ([]ToolStatus, error) {
+	pkgs, err := List(ctx, q, -1, false)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := BinDir(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("locating installed tools: %w", err)
+	}
+	bins, err := InstalledBinaries(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var tools []ToolStatus
+	for _, pkg := range pkgs {
+		if !IsTool(pkg) {
+			continue
+		}
+		tools = append(tools, toolStatus(pkg, bins))
+	}
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Package.Name < tools[j].Package.Name })
+	return tools, nil
+}
+
+func toolStatus(pkg data.Package, bins map[string]Binary) ToolStatus {
+	bin, ok := bins[pkg.Url]
+	if !ok {
+		return ToolStatus{Package: pkg, State: ToolMissing}
+	}
+
+	t := ToolStatus{Package: pkg, Binary: bin.File, Installed: bin.Version}
+	if saved := pkg.Version.String; saved != "" && saved != "latest" && saved != bin.Version {
+		t.State = ToolDrifted
+	}
+	return t
+}
+
+// SyncTools installs the saved tools that are missing or drifted with
+// 'go install', at their saved versions, and returns the tools it
+// installed. With dryRun it only returns them.
+func SyncTools(ctx context.Context, q data.Querier, dryRun bool, opts InstallOptions) ([]ToolStatus, error) {
+	tools, err := Tools(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []ToolStatus
+	var pkgs []data.Package
+	for _, t := range tools {
+		if t.State != ToolInstalled {
+			pending = append(pending, t)
+			pkgs = append(pkgs, t.Package)
+		}
+	}
+	if dryRun || len(pkgs) == 0 {
+		return pending, nil
+	}
+	return pending, InstallTools(ctx, q, pkgs, opts)
+}
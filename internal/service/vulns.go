@@ -0,0 +1,82 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lewvy/gopk/config"
+	"github.com/lewvy/gopk/internal/data"
+	"github.com/lewvy/gopk/internal/vulndb"
+)
+
+var ErrVulnerable = errors.New("known vulnerabilities")
+
+// OpenVulnDB opens the vulnerability database set in the config file or
+// $GOPK_VULNDB. It returns nil if none is configured.
+func OpenVulnDB(cfg config.Config) (*vulndb.DB, error) {
+	if cfg.Vuln.DB == "" {
+		return nil, nil
+	}
+	return vulndb.Open(cfg.Vuln.DB)
+}
+
+// PackageVulns matches the saved version of each package against db and
+// returns the affecting entries keyed by package ID. Packages saved without
+// a concrete version, such as "latest", are not matched.
+func PackageVulns(db *vulndb.DB, pkgs []data.Package) (map[int64][]*vulndb.Entry, error) {
+	vulns := make(map[int64][]*vulndb.Entry)
+	if db == nil {
+		return vulns, nil
+	}
+	for _, pkg := range pkgs {
+		entries, err := db.Vulns(ModulePath(pkg), pkg.Version.String)
+		if err != nil {
+			return nil, err
+		}
+		if len(entries) > 0 {
+			vulns[pkg.ID] = entries
+		}
+	}
+	return vulns, nil
+}
+
+func checkVulns(cfg config.Config, pkgs []data.Package, fail bool) error {
+	db, err := OpenVulnDB(cfg)
+	if err != nil || db == nil {
+		return err
+	}
+	defer db.Close()
+
+	vulns, err := PackageVulns(db, pkgs)
+	if err != nil {
+		return err
+	}
+
+	var affected []string
+	for _, pkg := range pkgs {
+		entries := vulns[pkg.ID]
+		if len(entries) == 0 {
+			continue
+		}
+		msg := fmt.Sprintf("%s@%s is affected by %s", pkg.Name, pkg.Version.String, VulnIDs(entries))
+		if fail {
+			affected = append(affected, msg)
+		} else {
+			Warn(msg)
+		}
+	}
+
+	if len(affected) > 0 {
+		return fmt.Errorf("%w: %s", ErrVulnerable, strings.Join(affected, "; "))
+	}
+	return nil
+}
+
+func VulnIDs(entries []*vulndb.Entry) string {
+	ids := make([]string, len(entries))
+	for i, e := range entries {
+		ids[i] = e.ID
+	}
+	return strings.Join(ids, ", ")
+}
@@ -0,0 +1,21 @@
+//go:build cgo && !purego
+
+package sqlite
+
+import (
+	"errors"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// Driver is the database/sql driver name to open the registry with.
+const Driver = "sqlite3"
+
+// IsUniqueConstraint reports whether err is a UNIQUE constraint violation.
+func IsUniqueConstraint(err error) bool {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
+	}
+	return false
+}
@@ -0,0 +1,22 @@
+//go:build !cgo || purego
+
+package sqlite
+
+import (
+	"errors"
+
+	modernc "modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// Driver is the database/sql driver name to open the registry with.
+const Driver = "sqlite"
+
+// IsUniqueConstraint reports whether err is a UNIQUE constraint violation.
+func IsUniqueConstraint(err error) bool {
+	var sqliteErr *modernc.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE
+	}
+	return false
+}
@@ -0,0 +1,6 @@
+// Package sqlite selects the database/sql driver used for the registry.
+//
+// Builds with cgo use github.com/mattn/go-sqlite3. Builds without cgo, or
+// with the purego build tag, use the pure-Go modernc.org/sqlite driver so
+// that gopk can be cross-compiled with CGO_ENABLED=0.
+package sqlite
@@ -0,0 +1,822 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lewvy/gopk/internal/data"
+)
+
+// jsonFile is the on-disk layout of the JSON backend. Rows mirror the
+// tables of the SQLite schema, and IDs are never reused, as with
+// AUTOINCREMENT.
+type jsonFile struct {
+	NextID struct {
+		Package int64 `json:"package"`
+		Group   int64 `json:"group"`
+		Snippet int64 `json:"snippet"`
+		Alias   int64 `json:"alias,omitempty"`
+	} `json:"next_id"`
+
+	Packages []jsonPackage     `json:"packages"`
+	Groups   []jsonGroup       `json:"groups"`
+	Members  []jsonMember      `json:"group_packages"`
+	Snippets []jsonSnippet     `json:"snippets"`
+	Info     []jsonPackageInfo `json:"package_info"`
+	Aliases  []jsonAlias       `json:"package_aliases,omitempty"`
+}
+
+type jsonPackage struct {
+	ID         int64   `json:"id"`
+	Name       string  `json:"name"`
+	URL        string  `json:"url"`
+	Module     string  `json:"module"`
+	ImportName string  `json:"import_name,omitempty"`
+	Version    *string `json:"version,omitempty"`
+	Kind       string  `json:"kind"`
+
+	ReplaceWith   string `json:"replace_with,omitempty"`
+	ReplaceAlways bool   `json:"replace_always,omitempty"`
+
+	Freq      int64     `json:"freq"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	LastUsed  time.Time `json:"last_used"`
+	Deleted   bool      `json:"deleted,omitempty"`
+}
+
+type jsonGroup struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type jsonMember struct {
+	GroupID   int64 `json:"group_id"`
+	PackageID int64 `json:"package_id"`
+}
+
+type jsonSnippet struct {
+	ID        int64     `json:"id"`
+	PackageID int64     `json:"package_id"`
+	Name      string    `json:"name"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type jsonPackageInfo struct {
+	PackageID   int64     `json:"package_id"`
+	Version     string    `json:"version"`
+	GoVersion   string    `json:"go_version,omitempty"`
+	Deps        int64     `json:"deps,omitempty"`
+	Synopsis    string    `json:"synopsis,omitempty"`
+	LicenseFile string    `json:"license_file,omitempty"`
+	License     string    `json:"license,omitempty"`
+	EnrichedAt  time.Time `json:"enriched_at"`
+}
+
+type jsonAlias struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	PackageID int64     `json:"package_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// JSONStore keeps the registry in a single JSON file. It needs no database
+// driver and is meant for minimal installs; every change rewrites the
+// whole file. It is not safe for use by several processes at once.
+type JSONStore struct {
+	mu   sync.Mutex
+	path string
+	f    jsonFile
+}
+
+var _ Store = (*JSONStore)(nil)
+
+// OpenJSON opens the registry stored at path, which is created on the
+// first change if it does not exist.
+func OpenJSON(path string) (*JSONStore, error) {
+	s := &JSONStore{path: path}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(content, &s.f); err != nil {
+		return nil, fmt.Errorf("invalid registry file %s: %w", path, err)
+	}
+	return s, nil
+}
+
+func (s *JSONStore) Close() error {
+	return nil
+}
+
+// save writes the registry to a temporary file and renames it over the
+// old one, so that a failed write never leaves a truncated registry.
+func (s *JSONStore) save() error {
+	content, err := json.MarshalIndent(&s.f, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".packages-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(content, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// now matches the second precision of SQLite's CURRENT_TIMESTAMP.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Second)
+}
+
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: true}
+}
+
+func (p jsonPackage) row() data.Package {
+	pkg := data.Package{
+		ID:            p.ID,
+		Name:          p.Name,
+		Url:           p.URL,
+		Module:        p.Module,
+		ImportName:    p.ImportName,
+		Kind:          p.Kind,
+		ReplaceWith:   p.ReplaceWith,
+		ReplaceAlways: p.ReplaceAlways,
+		Freq:          sql.NullInt64{Int64: p.Freq, Valid: true},
+		CreatedAt:     nullTime(p.CreatedAt),
+		UpdatedAt:     nullTime(p.UpdatedAt),
+		LastUsed:      nullTime(p.LastUsed),
+		IsDeleted:     sql.NullInt64{Valid: true},
+	}
+	if p.Version != nil {
+		pkg.Version = sql.NullString{String: *p.Version, Valid: true}
+	}
+	if p.Deleted {
+		pkg.IsDeleted.Int64 = 1
+	}
+	return pkg
+}
+
+func (g jsonGroup) row() data.Group {
+	return data.Group{
+		ID:        g.ID,
+		Name:      g.Name,
+		IsDeleted: sql.NullInt64{Valid: true},
+		CreatedAt: nullTime(g.CreatedAt),
+		UpdatedAt: nullTime(g.UpdatedAt),
+	}
+}
+
+func (sn jsonSnippet) row() data.Snippet {
+	return data.Snippet{
+		ID:        sn.ID,
+		PackageID: sn.PackageID,
+		Name:      sn.Name,
+		Body:      sn.Body,
+		CreatedAt: nullTime(sn.CreatedAt),
+		UpdatedAt: nullTime(sn.UpdatedAt),
+	}
+}
+
+func (i jsonPackageInfo) row() data.PackageInfo {
+	return data.PackageInfo{
+		PackageID:   i.PackageID,
+		Version:     i.Version,
+		GoVersion:   i.GoVersion,
+		Deps:        i.Deps,
+		Synopsis:    i.Synopsis,
+		LicenseFile: i.LicenseFile,
+		EnrichedAt:  nullTime(i.EnrichedAt),
+		License:     i.License,
+	}
+}
+
+func (a jsonAlias) row() data.PackageAlias {
+	return data.PackageAlias{
+		ID:        a.ID,
+		Name:      a.Name,
+		PackageID: a.PackageID,
+		CreatedAt: nullTime(a.CreatedAt),
+	}
+}
+
+func nullString(v sql.NullString) *string {
+	if !v.Valid {
+		return nil
+	}
+	return &v.String
+}
+
+func (s *JSONStore) packageIndex(match func(jsonPackage) bool) int {
+	return slices.IndexFunc(s.f.Packages, match)
+}
+
+func (s *JSONStore) groupIndex(name string) int {
+	return slices.IndexFunc(s.f.Groups, func(g jsonGroup) bool { return g.Name == name })
+}
+
+// checkUnique enforces the UNIQUE constraints on packages.name and
+// packages.url for the row at index i (-1 for a new row).
+func (s *JSONStore) checkUnique(i int, name, url string) error {
+	for j, p := range s.f.Packages {
+		if j == i {
+			continue
+		}
+		if p.Name == name {
+			return fmt.Errorf("%w: packages.name", ErrUnique)
+		}
+		if p.URL == url {
+			return fmt.Errorf("%w: packages.url", ErrUnique)
+		}
+	}
+	return nil
+}
+
+// deletePackages removes the packages matching del along with their group
+// memberships, snippets, metadata and extra aliases, like ON DELETE
+// CASCADE.
+func (s *JSONStore) deletePackages(del func(jsonPackage) bool) {
+	ids := make(map[int64]bool)
+	s.f.Packages = slices.DeleteFunc(s.f.Packages, func(p jsonPackage) bool {
+		if del(p) {
+			ids[p.ID] = true
+			return true
+		}
+		return false
+	})
+	s.f.Members = slices.DeleteFunc(s.f.Members, func(m jsonMember) bool { return ids[m.PackageID] })
+	s.f.Snippets = slices.DeleteFunc(s.f.Snippets, func(sn jsonSnippet) bool { return ids[sn.PackageID] })
+	s.f.Info = slices.DeleteFunc(s.f.Info, func(i jsonPackageInfo) bool { return ids[i.PackageID] })
+	s.f.Aliases = slices.DeleteFunc(s.f.Aliases, func(a jsonAlias) bool { return ids[a.PackageID] })
+}
+
+// listPackages returns the live packages sorted by less and truncated to
+// limit; a negative limit means no limit, as with SQLite's LIMIT.
+func (s *JSONStore) listPackages(limit int64, less func(a, b jsonPackage) bool) []data.Package {
+	var live []jsonPackage
+	for _, p := range s.f.Packages {
+		if !p.Deleted {
+			live = append(live, p)
+		}
+	}
+	sort.SliceStable(live, func(i, j int) bool { return less(live[i], live[j]) })
+	if limit >= 0 && int64(len(live)) > limit {
+		live = live[:limit]
+	}
+
+	pkgs := make([]data.Package, len(live))
+	for i, p := range live {
+		pkgs[i] = p.row()
+	}
+	return pkgs
+}
+
+// aliasRows returns the extra aliases matching match, sorted by name.
+func (s *JSONStore) aliasRows(match func(jsonAlias) bool) []data.PackageAlias {
+	var aliases []data.PackageAlias
+	for _, a := range s.f.Aliases {
+		if match(a) {
+			aliases = append(aliases, a.row())
+		}
+	}
+	sort.Slice(aliases, func(i, j int) bool { return aliases[i].Name < aliases[j].Name })
+	return aliases
+}
+
+func (s *JSONStore) AddPackageAlias(ctx context.Context, arg data.AddPackageAliasParams) (data.PackageAlias, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if slices.ContainsFunc(s.f.Aliases, func(a jsonAlias) bool { return a.Name == arg.Name }) {
+		return data.PackageAlias{}, fmt.Errorf("%w: package_aliases.name", ErrUnique)
+	}
+
+	s.f.NextID.Alias++
+	a := jsonAlias{ID: s.f.NextID.Alias, Name: arg.Name, PackageID: arg.PackageID, CreatedAt: now()}
+	s.f.Aliases = append(s.f.Aliases, a)
+	return a.row(), s.save()
+}
+
+func (s *JSONStore) AddPackageWithVersion(ctx context.Context, arg data.AddPackageWithVersionParams) (data.Package, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := now()
+	i := s.packageIndex(func(p jsonPackage) bool { return p.Name == arg.Name })
+	if err := s.checkUnique(i, arg.Name, arg.Url); err != nil {
+		return data.Package{}, err
+	}
+
+	if i < 0 {
+		s.f.NextID.Package++
+		s.f.Packages = append(s.f.Packages, jsonPackage{
+			ID:        s.f.NextID.Package,
+			Name:      arg.Name,
+			CreatedAt: t,
+			UpdatedAt: t,
+			LastUsed:  t,
+		})
+		i = len(s.f.Packages) - 1
+	}
+
+	p := &s.f.Packages[i]
+	p.URL = arg.Url
+	p.Module = arg.Module
+	p.ImportName = arg.ImportName
+	p.Version = nullString(arg.Version)
+	p.Kind = arg.Kind
+	p.Deleted = false
+
+	return p.row(), s.save()
+}
+
+func (s *JSONStore) AssignPackageToGroup(ctx context.Context, arg data.AssignPackageToGroupParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m := jsonMember{GroupID: arg.GroupID, PackageID: arg.PackageID}
+	if slices.Contains(s.f.Members, m) {
+		return nil
+	}
+	s.f.Members = append(s.f.Members, m)
+	return s.save()
+}
+
+func (s *JSONStore) CleanDatabase(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deletePackages(func(p jsonPackage) bool { return p.Deleted })
+	return s.save()
+}
+
+func (s *JSONStore) CreateGroup(ctx context.Context, name string) (data.Group, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.groupIndex(name) >= 0 {
+		return data.Group{}, fmt.Errorf("%w: groups.name", ErrUnique)
+	}
+
+	t := now()
+	s.f.NextID.Group++
+	g := jsonGroup{ID: s.f.NextID.Group, Name: name, CreatedAt: t, UpdatedAt: t}
+	s.f.Groups = append(s.f.Groups, g)
+	return g.row(), s.save()
+}
+
+func (s *JSONStore) DeleteGroup(ctx context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.groupIndex(name)
+	if i < 0 {
+		return nil
+	}
+	id := s.f.Groups[i].ID
+	s.f.Groups = slices.Delete(s.f.Groups, i, i+1)
+	s.f.Members = slices.DeleteFunc(s.f.Members, func(m jsonMember) bool { return m.GroupID == id })
+	return s.save()
+}
+
+func (s *JSONStore) DeletePackageAlias(ctx context.Context, name string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := len(s.f.Aliases)
+	s.f.Aliases = slices.DeleteFunc(s.f.Aliases, func(a jsonAlias) bool { return a.Name == name })
+	deleted := int64(n - len(s.f.Aliases))
+	if deleted == 0 {
+		return 0, nil
+	}
+	return deleted, s.save()
+}
+
+func (s *JSONStore) DeletePackagesByName(ctx context.Context, names []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deletePackages(func(p jsonPackage) bool { return slices.Contains(names, p.Name) })
+	return s.save()
+}
+
+func (s *JSONStore) DeleteSnippet(ctx context.Context, arg data.DeleteSnippetParams) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := len(s.f.Snippets)
+	s.f.Snippets = slices.DeleteFunc(s.f.Snippets, func(sn jsonSnippet) bool {
+		return sn.PackageID == arg.PackageID && sn.Name == arg.Name
+	})
+	deleted := int64(n - len(s.f.Snippets))
+	if deleted == 0 {
+		return 0, nil
+	}
+	return deleted, s.save()
+}
+
+func (s *JSONStore) GetGroupIDByName(ctx context.Context, name string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.groupIndex(name)
+	if i < 0 {
+		return 0, sql.ErrNoRows
+	}
+	return s.f.Groups[i].ID, nil
+}
+
+func (s *JSONStore) GetIDByName(ctx context.Context, name string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.packageIndex(func(p jsonPackage) bool { return p.Name == name })
+	if i < 0 {
+		return 0, sql.ErrNoRows
+	}
+	return s.f.Packages[i].ID, nil
+}
+
+func (s *JSONStore) GetPackageAlias(ctx context.Context, name string) (data.PackageAlias, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := slices.IndexFunc(s.f.Aliases, func(a jsonAlias) bool { return a.Name == name })
+	if i < 0 {
+		return data.PackageAlias{}, sql.ErrNoRows
+	}
+	return s.f.Aliases[i].row(), nil
+}
+
+func (s *JSONStore) GetPackageAliasesByNames(ctx context.Context, names []string) ([]data.PackageAlias, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.aliasRows(func(a jsonAlias) bool { return slices.Contains(names, a.Name) }), nil
+}
+
+func (s *JSONStore) GetPackageByID(ctx context.Context, id int64) (data.Package, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.packageIndex(func(p jsonPackage) bool { return p.ID == id && !p.Deleted })
+	if i < 0 {
+		return data.Package{}, sql.ErrNoRows
+	}
+	return s.f.Packages[i].row(), nil
+}
+
+func (s *JSONStore) GetPackageByName(ctx context.Context, name string) (data.Package, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.packageIndex(func(p jsonPackage) bool { return p.Name == name && !p.Deleted })
+	if i < 0 {
+		return data.Package{}, sql.ErrNoRows
+	}
+	return s.f.Packages[i].row(), nil
+}
+
+func (s *JSONStore) GetPackageIDByURL(ctx context.Context, url string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.packageIndex(func(p jsonPackage) bool { return p.URL == url })
+	if i < 0 {
+		return 0, sql.ErrNoRows
+	}
+	return s.f.Packages[i].ID, nil
+}
+
+func (s *JSONStore) GetPackageIDsByURLs(ctx context.Context, urls []string) ([]int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ids []int64
+	for _, p := range s.f.Packages {
+		if slices.Contains(urls, p.URL) {
+			ids = append(ids, p.ID)
+		}
+	}
+	return ids, nil
+}
+
+func (s *JSONStore) GetPackageInfo(ctx context.Context, packageID int64) (data.PackageInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, i := range s.f.Info {
+		if i.PackageID == packageID {
+			return i.row(), nil
+		}
+	}
+	return data.PackageInfo{}, sql.ErrNoRows
+}
+
+func (s *JSONStore) GetSnippet(ctx context.Context, arg data.GetSnippetParams) (data.Snippet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, sn := range s.f.Snippets {
+		if sn.PackageID == arg.PackageID && sn.Name == arg.Name {
+			return sn.row(), nil
+		}
+	}
+	return data.Snippet{}, sql.ErrNoRows
+}
+
+func (s *JSONStore) GetURLsByNames(ctx context.Context, names []string) ([]data.Package, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	aliased := make(map[int64]bool)
+	for _, a := range s.f.Aliases {
+		if slices.Contains(names, a.Name) {
+			aliased[a.PackageID] = true
+		}
+	}
+
+	var pkgs []data.Package
+	for _, p := range s.f.Packages {
+		if slices.Contains(names, p.Name) || aliased[p.ID] {
+			pkgs = append(pkgs, p.row())
+		}
+	}
+	return pkgs, nil
+}
+
+func (s *JSONStore) ListAliasesByPackage(ctx context.Context, packageID int64) ([]data.PackageAlias, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.aliasRows(func(a jsonAlias) bool { return a.PackageID == packageID }), nil
+}
+
+func (s *JSONStore) ListGroups(ctx context.Context) ([]data.Group, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	groups := make([]data.Group, len(s.f.Groups))
+	for i, g := range s.f.Groups {
+		groups[i] = g.row()
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+	return groups, nil
+}
+
+func (s *JSONStore) ListPackageAliases(ctx context.Context) ([]data.PackageAlias, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.aliasRows(func(jsonAlias) bool { return true }), nil
+}
+
+func (s *JSONStore) ListPackageInfo(ctx context.Context) ([]data.PackageInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var infos []data.PackageInfo
+	for _, i := range s.f.Info {
+		live := s.packageIndex(func(p jsonPackage) bool { return p.ID == i.PackageID && !p.Deleted })
+		if live >= 0 {
+			infos = append(infos, i.row())
+		}
+	}
+	return infos, nil
+}
+
+func (s *JSONStore) ListPackagesByFrequency(ctx context.Context, limit int64) ([]data.Package, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.listPackages(limit, func(a, b jsonPackage) bool { return a.Freq > b.Freq }), nil
+}
+
+func (s *JSONStore) ListPackagesByGroup(ctx context.Context, name string) ([]data.Package, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.groupIndex(name)
+	if i < 0 {
+		return nil, nil
+	}
+	id := s.f.Groups[i].ID
+
+	var pkgs []data.Package
+	for _, p := range s.f.Packages {
+		member := slices.Contains(s.f.Members, jsonMember{GroupID: id, PackageID: p.ID})
+		if member && !p.Deleted {
+			pkgs = append(pkgs, p.row())
+		}
+	}
+	sort.Slice(pkgs, func(i, j int) bool { return pkgs[i].Name < pkgs[j].Name })
+	return pkgs, nil
+}
+
+func (s *JSONStore) ListPackagesByLastUsed(ctx context.Context, limit int64) ([]data.Package, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.listPackages(limit, func(a, b jsonPackage) bool { return a.LastUsed.After(b.LastUsed) }), nil
+}
+
+func (s *JSONStore) ListSnippetsByPackage(ctx context.Context, packageID int64) ([]data.Snippet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var snippets []data.Snippet
+	for _, sn := range s.f.Snippets {
+		if sn.PackageID == packageID {
+			snippets = append(snippets, sn.row())
+		}
+	}
+	sort.Slice(snippets, func(i, j int) bool { return snippets[i].Name < snippets[j].Name })
+	return snippets, nil
+}
+
+func (s *JSONStore) markDeleted(names []string, deleted bool) error {
+	t := now()
+	for i, p := range s.f.Packages {
+		if slices.Contains(names, p.Name) {
+			s.f.Packages[i].Deleted = deleted
+			s.f.Packages[i].UpdatedAt = t
+		}
+	}
+	return s.save()
+}
+
+func (s *JSONStore) MarkDeleteByName(ctx context.Context, names []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.markDeleted(names, true)
+}
+
+func (s *JSONStore) MarkDeleteFalse(ctx context.Context, names []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.markDeleted(names, false)
+}
+
+func (s *JSONStore) RemovePackagesFromGroup(ctx context.Context, arg data.RemovePackagesFromGroupParams) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.f.Members = slices.DeleteFunc(s.f.Members, func(m jsonMember) bool {
+		return m.GroupID == arg.GroupID && slices.Contains(arg.PackageIds, m.PackageID)
+	})
+	return s.save()
+}
+
+func (s *JSONStore) SetPackageReplace(ctx context.Context, arg data.SetPackageReplaceParams) (data.Package, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.packageIndex(func(p jsonPackage) bool { return p.Name == arg.Name && !p.Deleted })
+	if i < 0 {
+		return data.Package{}, sql.ErrNoRows
+	}
+
+	p := &s.f.Packages[i]
+	p.ReplaceWith = arg.ReplaceWith
+	p.ReplaceAlways = arg.ReplaceAlways
+	p.UpdatedAt = now()
+	return p.row(), s.save()
+}
+
+func (s *JSONStore) UpdatePackage(ctx context.Context, arg data.UpdatePackageParams) (data.Package, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.packageIndex(func(p jsonPackage) bool { return p.ID == arg.ID })
+	if i < 0 {
+		return data.Package{}, sql.ErrNoRows
+	}
+	if err := s.checkUnique(i, arg.Name, arg.Url); err != nil {
+		return data.Package{}, err
+	}
+
+	p := &s.f.Packages[i]
+	p.Name = arg.Name
+	p.URL = arg.Url
+	p.Module = arg.Module
+	p.ImportName = arg.ImportName
+	p.Version = nullString(arg.Version)
+	p.Kind = arg.Kind
+	return p.row(), s.save()
+}
+
+func (s *JSONStore) UpdatePackageByName(ctx context.Context, arg data.UpdatePackageByNameParams) (data.Package, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.packageIndex(func(p jsonPackage) bool { return p.Name == arg.Name })
+	if i < 0 {
+		return data.Package{}, sql.ErrNoRows
+	}
+	if err := s.checkUnique(i, arg.Name, arg.Url); err != nil {
+		return data.Package{}, err
+	}
+
+	p := &s.f.Packages[i]
+	p.URL = arg.Url
+	p.Module = arg.Module
+	p.ImportName = arg.ImportName
+	p.Version = nullString(arg.Version)
+	p.Kind = arg.Kind
+	return p.row(), s.save()
+}
+
+func (s *JSONStore) UpdatePackageUsage(ctx context.Context, url string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := now()
+	for i, p := range s.f.Packages {
+		if p.URL == url {
+			s.f.Packages[i].Freq++
+			s.f.Packages[i].LastUsed = t
+		}
+	}
+	return s.save()
+}
+
+func (s *JSONStore) UpsertPackageInfo(ctx context.Context, arg data.UpsertPackageInfoParams) (data.PackageInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info := jsonPackageInfo{
+		PackageID:   arg.PackageID,
+		Version:     arg.Version,
+		GoVersion:   arg.GoVersion,
+		Deps:        arg.Deps,
+		Synopsis:    arg.Synopsis,
+		LicenseFile: arg.LicenseFile,
+		License:     arg.License,
+		EnrichedAt:  now(),
+	}
+
+	i := slices.IndexFunc(s.f.Info, func(i jsonPackageInfo) bool { return i.PackageID == arg.PackageID })
+	if i < 0 {
+		s.f.Info = append(s.f.Info, info)
+	} else {
+		s.f.Info[i] = info
+	}
+	return info.row(), s.save()
+}
+
+func (s *JSONStore) UpsertSnippet(ctx context.Context, arg data.UpsertSnippetParams) (data.Snippet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := now()
+	i := slices.IndexFunc(s.f.Snippets, func(sn jsonSnippet) bool {
+		return sn.PackageID == arg.PackageID && sn.Name == arg.Name
+	})
+	if i < 0 {
+		s.f.NextID.Snippet++
+		s.f.Snippets = append(s.f.Snippets, jsonSnippet{
+			ID:        s.f.NextID.Snippet,
+			PackageID: arg.PackageID,
+			Name:      arg.Name,
+			CreatedAt: t,
+		})
+		i = len(s.f.Snippets) - 1
+	}
+
+	sn := &s.f.Snippets[i]
+	sn.Body = arg.Body
+	sn.UpdatedAt = t
+	return sn.row(), s.save()
+}
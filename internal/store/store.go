@@ -0,0 +1,102 @@
+// Package store opens the storage backend of the registry.
+//
+// The service layer only depends on data.Querier. The default backend is
+// the sqlc-generated SQLite implementation; a JSON-file backend is
+// available for installs that cannot or do not want to use SQLite.
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/lewvy/gopk/config"
+	"github.com/lewvy/gopk/internal/data"
+	"github.com/lewvy/gopk/internal/sqlite"
+)
+
+// ErrUnique is returned by backends other than SQLite when a change would
+// violate a uniqueness constraint of the schema.
+var ErrUnique = errors.New("UNIQUE constraint failed")
+
+// Store is a registry backend.
+type Store interface {
+	data.Querier
+	Close() error
+}
+
+// IsUniqueConstraint reports whether err is a uniqueness violation from
+// any backend.
+func IsUniqueConstraint(err error) bool {
+	return errors.Is(err, ErrUnique) || sqlite.IsUniqueConstraint(err)
+}
+
+type sqliteStore struct {
+	*data.Queries
+	db *sql.DB
+}
+
+func (s sqliteStore) Close() error {
+	return s.db.Close()
+}
+
+// NewSQLite returns a Store backed by an open, migrated SQLite database.
+func NewSQLite(db *sql.DB) Store {
+	return sqliteStore{Queries: data.New(db), db: db}
+}
+
+// Open opens the registry of the active profile with the backend set in
+// the config file.
+func Open() (Store, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	switch cfg.Storage.Backend {
+	case "json":
+		path, err := jsonPath()
+		if err != nil {
+			return nil, err
+		}
+		return OpenJSON(path)
+	default:
+		db, err := config.InitDB()
+		if err != nil {
+			return nil, err
+		}
+		return NewSQLite(db), nil
+	}
+}
+
+func jsonPath() (string, error) {
+	dir, err := config.ProfileDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "packages.json"), nil
+}
+
+// Reset empties the registry of the active profile after backing it up.
+func Reset() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if cfg.Storage.Backend != "json" {
+		return config.ResetDB()
+	}
+
+	path, err := jsonPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(path, path+".bak"); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("backup failed: %w", err)
+	}
+	fmt.Printf("Backup saved to: %s.bak\n", path)
+	fmt.Println("reset done")
+	return nil
+}
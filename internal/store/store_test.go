@@ -0,0 +1,36 @@
+package store_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/lewvy/gopk/internal/sqlite"
+	"github.com/lewvy/gopk/internal/store"
+	"github.com/lewvy/gopk/internal/store/storetest"
+)
+
+func TestJSONStore(t *testing.T) {
+	s, err := store.OpenJSON(filepath.Join(t.TempDir(), "packages.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	storetest.TestStore(t, s)
+}
+
+// TestSQLiteStore runs against the driver the build selects: go-sqlite3
+// with cgo, modernc.org/sqlite with -tags purego or CGO_ENABLED=0.
+func TestSQLiteStore(t *testing.T) {
+	t.Setenv("GOPK_DB_DIR", t.TempDir())
+	t.Setenv("GOPK_CONFIG_DIR", t.TempDir())
+	t.Setenv("GOPK_PROFILE", "")
+	t.Setenv("GOPK_STORAGE", "sqlite")
+
+	s, err := store.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	t.Logf("driver %s", sqlite.Driver)
+	storetest.TestStore(t, s)
+}
@@ -0,0 +1,411 @@
+// Package storetest checks that a registry backend behaves like the SQLite
+// schema and queries the service layer was written against.
+package storetest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/lewvy/gopk/internal/data"
+	"github.com/lewvy/gopk/internal/store"
+)
+
+// TestStore runs the conformance checks against s, which must be empty,
+// as one subtest per group of operations. The subtests share s and run in
+// order, each starting from the state the previous ones left.
+func TestStore(t *testing.T, s data.Querier) {
+	steps := []struct {
+		name string
+		run  func(*checker)
+	}{
+		{"Packages", (*checker).packages},
+		{"Usage", (*checker).usage},
+		{"SoftDelete", (*checker).softDelete},
+		{"Groups", (*checker).groups},
+		{"Snippets", (*checker).snippets},
+		{"Aliases", (*checker).aliases},
+		{"PackageInfo", (*checker).packageInfo},
+		{"Deletion", (*checker).deletion},
+	}
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			step.run(&checker{t: t, ctx: context.Background(), s: s})
+		})
+	}
+}
+
+type checker struct {
+	t   *testing.T
+	ctx context.Context
+	s   data.Querier
+}
+
+func (c *checker) errorf(format string, args ...any) {
+	c.t.Helper()
+	c.t.Errorf(format, args...)
+}
+
+// ok records err as a failure of op and reports whether it was nil.
+func (c *checker) ok(op string, err error) bool {
+	c.t.Helper()
+	if err != nil {
+		c.errorf("%s: %v", op, err)
+		return false
+	}
+	return true
+}
+
+func (c *checker) noRows(op string, err error) {
+	c.t.Helper()
+	if !errors.Is(err, sql.ErrNoRows) {
+		c.errorf("%s: got error %v, want sql.ErrNoRows", op, err)
+	}
+}
+
+func (c *checker) add(name, url string) data.Package {
+	c.t.Helper()
+	pkg, err := c.s.AddPackageWithVersion(c.ctx, data.AddPackageWithVersionParams{
+		Name:    name,
+		Url:     url,
+		Module:  url,
+		Version: sql.NullString{String: "v1.0.0", Valid: true},
+		Kind:    "library",
+	})
+	c.ok("AddPackageWithVersion "+name, err)
+	return pkg
+}
+
+func names(pkgs []data.Package) []string {
+	out := make([]string, len(pkgs))
+	for i, p := range pkgs {
+		out[i] = p.Name
+	}
+	return out
+}
+
+func (c *checker) packages() {
+	chi := c.add("chi", "github.com/go-chi/chi/v5")
+	if chi.ID == 0 || chi.Name != "chi" || chi.Url != "github.com/go-chi/chi/v5" ||
+		chi.Version.String != "v1.0.0" || chi.Freq.Int64 != 0 || chi.IsDeleted.Int64 != 0 {
+		c.errorf("AddPackageWithVersion returned %+v", chi)
+	}
+
+	cobra := c.add("cobra", "github.com/spf13/cobra")
+	if cobra.ID == chi.ID {
+		c.errorf("AddPackageWithVersion reused ID %d", chi.ID)
+	}
+
+	again, err := c.s.AddPackageWithVersion(c.ctx, data.AddPackageWithVersionParams{
+		Name:       "chi",
+		Url:        "github.com/go-chi/chi/v5/middleware",
+		Module:     "github.com/go-chi/chi/v5",
+		ImportName: "mw",
+		Version:    sql.NullString{String: "v5.2.0", Valid: true},
+		Kind:       "both",
+	})
+	if c.ok("AddPackageWithVersion existing name", err) &&
+		(again.ID != chi.ID || again.Url != "github.com/go-chi/chi/v5/middleware" || again.ImportName != "mw" || again.Kind != "both") {
+		c.errorf("AddPackageWithVersion on an existing name: got %+v, want update of ID %d", again, chi.ID)
+	}
+
+	_, err = c.s.AddPackageWithVersion(c.ctx, data.AddPackageWithVersionParams{
+		Name: "cobra2",
+		Url:  "github.com/spf13/cobra",
+	})
+	if !store.IsUniqueConstraint(err) {
+		c.errorf("AddPackageWithVersion with a duplicate URL: got error %v, want unique constraint", err)
+	}
+
+	if got, err := c.s.GetPackageByName(c.ctx, "cobra"); c.ok("GetPackageByName", err) && got.ID != cobra.ID {
+		c.errorf("GetPackageByName: got ID %d, want %d", got.ID, cobra.ID)
+	}
+	if got, err := c.s.GetPackageByID(c.ctx, cobra.ID); c.ok("GetPackageByID", err) && got.Name != "cobra" {
+		c.errorf("GetPackageByID: got %q, want cobra", got.Name)
+	}
+	if id, err := c.s.GetIDByName(c.ctx, "cobra"); c.ok("GetIDByName", err) && id != cobra.ID {
+		c.errorf("GetIDByName: got %d, want %d", id, cobra.ID)
+	}
+	if id, err := c.s.GetPackageIDByURL(c.ctx, cobra.Url); c.ok("GetPackageIDByURL", err) && id != cobra.ID {
+		c.errorf("GetPackageIDByURL: got %d, want %d", id, cobra.ID)
+	}
+
+	_, err = c.s.GetPackageByName(c.ctx, "missing")
+	c.noRows("GetPackageByName missing", err)
+	_, err = c.s.GetIDByName(c.ctx, "missing")
+	c.noRows("GetIDByName missing", err)
+	_, err = c.s.GetPackageIDByURL(c.ctx, "example.com/missing")
+	c.noRows("GetPackageIDByURL missing", err)
+
+	ids, err := c.s.GetPackageIDsByURLs(c.ctx, []string{cobra.Url, "example.com/missing"})
+	if c.ok("GetPackageIDsByURLs", err) && !slices.Equal(ids, []int64{cobra.ID}) {
+		c.errorf("GetPackageIDsByURLs: got %v, want [%d]", ids, cobra.ID)
+	}
+
+	pkgs, err := c.s.GetURLsByNames(c.ctx, []string{"chi", "cobra", "missing"})
+	if c.ok("GetURLsByNames", err) && len(pkgs) != 2 {
+		c.errorf("GetURLsByNames: got %v, want chi and cobra", names(pkgs))
+	}
+
+	renamed, err := c.s.UpdatePackage(c.ctx, data.UpdatePackageParams{
+		ID:      cobra.ID,
+		Name:    "cobra-cli",
+		Url:     cobra.Url,
+		Module:  cobra.Module,
+		Version: sql.NullString{String: "v1.8.0", Valid: true},
+		Kind:    "tool",
+	})
+	if c.ok("UpdatePackage", err) && (renamed.Name != "cobra-cli" || renamed.Version.String != "v1.8.0" || renamed.Kind != "tool") {
+		c.errorf("UpdatePackage: got %+v", renamed)
+	}
+	_, err = c.s.UpdatePackage(c.ctx, data.UpdatePackageParams{ID: cobra.ID, Name: "chi", Url: cobra.Url})
+	if !store.IsUniqueConstraint(err) {
+		c.errorf("UpdatePackage to a taken name: got error %v, want unique constraint", err)
+	}
+
+	byName, err := c.s.UpdatePackageByName(c.ctx, data.UpdatePackageByNameParams{
+		Name:    "cobra-cli",
+		Url:     cobra.Url,
+		Module:  cobra.Module,
+		Version: sql.NullString{String: "v1.9.0", Valid: true},
+		Kind:    "library",
+	})
+	if c.ok("UpdatePackageByName", err) && (byName.ID != cobra.ID || byName.Version.String != "v1.9.0" || byName.Kind != "library") {
+		c.errorf("UpdatePackageByName: got %+v", byName)
+	}
+	_, err = c.s.UpdatePackageByName(c.ctx, data.UpdatePackageByNameParams{Name: "missing", Url: "example.com/missing"})
+	c.noRows("UpdatePackageByName missing", err)
+
+	replaced, err := c.s.SetPackageReplace(c.ctx, data.SetPackageReplaceParams{
+		Name:          "cobra-cli",
+		ReplaceWith:   "/src/cobra",
+		ReplaceAlways: true,
+	})
+	if c.ok("SetPackageReplace", err) && (replaced.ID != cobra.ID || replaced.ReplaceWith != "/src/cobra" || !replaced.ReplaceAlways) {
+		c.errorf("SetPackageReplace: got %+v", replaced)
+	}
+	if got, err := c.s.GetPackageByID(c.ctx, cobra.ID); c.ok("GetPackageByID", err) && got.ReplaceWith != "/src/cobra" {
+		c.errorf("SetPackageReplace: replacement not saved, got %+v", got)
+	}
+	_, err = c.s.SetPackageReplace(c.ctx, data.SetPackageReplaceParams{Name: "missing"})
+	c.noRows("SetPackageReplace missing", err)
+}
+
+func (c *checker) usage() {
+	c.add("zerolog", "github.com/rs/zerolog")
+
+	for range 3 {
+		c.ok("UpdatePackageUsage", c.s.UpdatePackageUsage(c.ctx, "github.com/rs/zerolog"))
+	}
+	c.ok("UpdatePackageUsage", c.s.UpdatePackageUsage(c.ctx, "github.com/spf13/cobra"))
+
+	pkgs, err := c.s.ListPackagesByFrequency(c.ctx, 2)
+	if c.ok("ListPackagesByFrequency", err) {
+		if got := names(pkgs); !slices.Equal(got, []string{"zerolog", "cobra-cli"}) {
+			c.errorf("ListPackagesByFrequency(2): got %v, want [zerolog cobra-cli]", got)
+		} else if pkgs[0].Freq.Int64 != 3 {
+			c.errorf("UpdatePackageUsage: got freq %d, want 3", pkgs[0].Freq.Int64)
+		}
+	}
+
+	all, err := c.s.ListPackagesByLastUsed(c.ctx, -1)
+	if c.ok("ListPackagesByLastUsed", err) && len(all) != 3 {
+		c.errorf("ListPackagesByLastUsed(-1): got %v, want 3 packages", names(all))
+	}
+}
+
+func (c *checker) softDelete() {
+	c.ok("MarkDeleteByName", c.s.MarkDeleteByName(c.ctx, []string{"zerolog"}))
+
+	_, err := c.s.GetPackageByName(c.ctx, "zerolog")
+	c.noRows("GetPackageByName after MarkDeleteByName", err)
+
+	pkgs, err := c.s.ListPackagesByLastUsed(c.ctx, -1)
+	if c.ok("ListPackagesByLastUsed", err) && slices.Contains(names(pkgs), "zerolog") {
+		c.errorf("ListPackagesByLastUsed lists a deleted package")
+	}
+
+	pkgs, err = c.s.GetURLsByNames(c.ctx, []string{"zerolog"})
+	if c.ok("GetURLsByNames", err) && (len(pkgs) != 1 || pkgs[0].IsDeleted.Int64 == 0) {
+		c.errorf("GetURLsByNames: got %+v, want zerolog marked deleted", pkgs)
+	}
+
+	c.ok("MarkDeleteFalse", c.s.MarkDeleteFalse(c.ctx, []string{"zerolog"}))
+	if _, err := c.s.GetPackageByName(c.ctx, "zerolog"); err != nil {
+		c.errorf("GetPackageByName after MarkDeleteFalse: %v", err)
+	}
+}
+
+func (c *checker) groups() {
+	web, err := c.s.CreateGroup(c.ctx, "web")
+	c.ok("CreateGroup", err)
+	_, err = c.s.CreateGroup(c.ctx, "cli")
+	c.ok("CreateGroup", err)
+	if _, err := c.s.CreateGroup(c.ctx, "web"); !store.IsUniqueConstraint(err) {
+		c.errorf("CreateGroup with a duplicate name: got error %v, want unique constraint", err)
+	}
+
+	groups, err := c.s.ListGroups(c.ctx)
+	if c.ok("ListGroups", err) && (len(groups) != 2 || groups[0].Name != "cli" || groups[1].Name != "web") {
+		c.errorf("ListGroups: got %+v, want cli and web", groups)
+	}
+
+	if id, err := c.s.GetGroupIDByName(c.ctx, "web"); c.ok("GetGroupIDByName", err) && id != web.ID {
+		c.errorf("GetGroupIDByName: got %d, want %d", id, web.ID)
+	}
+	_, err = c.s.GetGroupIDByName(c.ctx, "missing")
+	c.noRows("GetGroupIDByName missing", err)
+
+	chi, _ := c.s.GetIDByName(c.ctx, "chi")
+	zerolog, _ := c.s.GetIDByName(c.ctx, "zerolog")
+	for _, id := range []int64{zerolog, chi, chi} {
+		c.ok("AssignPackageToGroup", c.s.AssignPackageToGroup(c.ctx, data.AssignPackageToGroupParams{
+			GroupID:   web.ID,
+			PackageID: id,
+		}))
+	}
+
+	pkgs, err := c.s.ListPackagesByGroup(c.ctx, "web")
+	if c.ok("ListPackagesByGroup", err) && !slices.Equal(names(pkgs), []string{"chi", "zerolog"}) {
+		c.errorf("ListPackagesByGroup: got %v, want [chi zerolog]", names(pkgs))
+	}
+
+	c.ok("RemovePackagesFromGroup", c.s.RemovePackagesFromGroup(c.ctx, data.RemovePackagesFromGroupParams{
+		GroupID:    web.ID,
+		PackageIds: []int64{zerolog},
+	}))
+	pkgs, err = c.s.ListPackagesByGroup(c.ctx, "web")
+	if c.ok("ListPackagesByGroup", err) && !slices.Equal(names(pkgs), []string{"chi"}) {
+		c.errorf("ListPackagesByGroup after RemovePackagesFromGroup: got %v, want [chi]", names(pkgs))
+	}
+
+	c.ok("DeleteGroup", c.s.DeleteGroup(c.ctx, "cli"))
+	_, err = c.s.GetGroupIDByName(c.ctx, "cli")
+	c.noRows("GetGroupIDByName after DeleteGroup", err)
+}
+
+func (c *checker) snippets() {
+	chi, _ := c.s.GetIDByName(c.ctx, "chi")
+
+	first, err := c.s.UpsertSnippet(c.ctx, data.UpsertSnippetParams{PackageID: chi, Name: "router", Body: "r := chi.NewRouter()"})
+	c.ok("UpsertSnippet", err)
+	_, err = c.s.UpsertSnippet(c.ctx, data.UpsertSnippetParams{PackageID: chi, Name: "mount", Body: "r.Mount(\"/\", h)"})
+	c.ok("UpsertSnippet", err)
+
+	updated, err := c.s.UpsertSnippet(c.ctx, data.UpsertSnippetParams{PackageID: chi, Name: "router", Body: "r := chi.NewMux()"})
+	if c.ok("UpsertSnippet existing", err) && (updated.ID != first.ID || updated.Body != "r := chi.NewMux()") {
+		c.errorf("UpsertSnippet on an existing name: got %+v, want update of ID %d", updated, first.ID)
+	}
+
+	snippets, err := c.s.ListSnippetsByPackage(c.ctx, chi)
+	if c.ok("ListSnippetsByPackage", err) && (len(snippets) != 2 || snippets[0].Name != "mount") {
+		c.errorf("ListSnippetsByPackage: got %+v, want mount and router", snippets)
+	}
+
+	got, err := c.s.GetSnippet(c.ctx, data.GetSnippetParams{PackageID: chi, Name: "router"})
+	if c.ok("GetSnippet", err) && got.Body != "r := chi.NewMux()" {
+		c.errorf("GetSnippet: got body %q", got.Body)
+	}
+
+	n, err := c.s.DeleteSnippet(c.ctx, data.DeleteSnippetParams{PackageID: chi, Name: "mount"})
+	if c.ok("DeleteSnippet", err) && n != 1 {
+		c.errorf("DeleteSnippet: got %d rows, want 1", n)
+	}
+	n, err = c.s.DeleteSnippet(c.ctx, data.DeleteSnippetParams{PackageID: chi, Name: "mount"})
+	if c.ok("DeleteSnippet", err) && n != 0 {
+		c.errorf("DeleteSnippet of a missing snippet: got %d rows, want 0", n)
+	}
+	_, err = c.s.GetSnippet(c.ctx, data.GetSnippetParams{PackageID: chi, Name: "mount"})
+	c.noRows("GetSnippet missing", err)
+}
+
+func (c *checker) aliases() {
+	chi, _ := c.s.GetIDByName(c.ctx, "chi")
+
+	for _, name := range []string{"router", "go-chi"} {
+		a, err := c.s.AddPackageAlias(c.ctx, data.AddPackageAliasParams{Name: name, PackageID: chi})
+		if c.ok("AddPackageAlias", err) && (a.Name != name || a.PackageID != chi) {
+			c.errorf("AddPackageAlias: got %+v", a)
+		}
+	}
+	if _, err := c.s.AddPackageAlias(c.ctx, data.AddPackageAliasParams{Name: "router", PackageID: chi}); !store.IsUniqueConstraint(err) {
+		c.errorf("AddPackageAlias with a taken name: got error %v, want unique constraint", err)
+	}
+
+	if a, err := c.s.GetPackageAlias(c.ctx, "router"); c.ok("GetPackageAlias", err) && a.PackageID != chi {
+		c.errorf("GetPackageAlias: got %+v, want package %d", a, chi)
+	}
+	_, err := c.s.GetPackageAlias(c.ctx, "missing")
+	c.noRows("GetPackageAlias missing", err)
+
+	aliases, err := c.s.ListAliasesByPackage(c.ctx, chi)
+	if c.ok("ListAliasesByPackage", err) && (len(aliases) != 2 || aliases[0].Name != "go-chi") {
+		c.errorf("ListAliasesByPackage: got %+v, want go-chi and router", aliases)
+	}
+	if all, err := c.s.ListPackageAliases(c.ctx); c.ok("ListPackageAliases", err) && len(all) != 2 {
+		c.errorf("ListPackageAliases: got %+v, want 2 aliases", all)
+	}
+	found, err := c.s.GetPackageAliasesByNames(c.ctx, []string{"router", "chi", "missing"})
+	if c.ok("GetPackageAliasesByNames", err) && (len(found) != 1 || found[0].Name != "router") {
+		c.errorf("GetPackageAliasesByNames: got %+v, want router", found)
+	}
+
+	pkgs, err := c.s.GetURLsByNames(c.ctx, []string{"router", "go-chi", "chi"})
+	if c.ok("GetURLsByNames by alias", err) && !slices.Equal(names(pkgs), []string{"chi"}) {
+		c.errorf("GetURLsByNames by alias: got %v, want [chi] once", names(pkgs))
+	}
+
+	n, err := c.s.DeletePackageAlias(c.ctx, "go-chi")
+	if c.ok("DeletePackageAlias", err) && n != 1 {
+		c.errorf("DeletePackageAlias: got %d rows, want 1", n)
+	}
+	n, err = c.s.DeletePackageAlias(c.ctx, "go-chi")
+	if c.ok("DeletePackageAlias", err) && n != 0 {
+		c.errorf("DeletePackageAlias of a missing alias: got %d rows, want 0", n)
+	}
+	pkgs, err = c.s.GetURLsByNames(c.ctx, []string{"go-chi"})
+	if c.ok("GetURLsByNames", err) && len(pkgs) != 0 {
+		c.errorf("GetURLsByNames after DeletePackageAlias: got %v, want none", names(pkgs))
+	}
+}
+
+func (c *checker) packageInfo() {
+	chi, _ := c.s.GetIDByName(c.ctx, "chi")
+	zerolog, _ := c.s.GetIDByName(c.ctx, "zerolog")
+
+	for _, id := range []int64{chi, zerolog} {
+		_, err := c.s.UpsertPackageInfo(c.ctx, data.UpsertPackageInfoParams{PackageID: id, Version: "v1.0.0", License: "MIT"})
+		c.ok("UpsertPackageInfo", err)
+	}
+	info, err := c.s.UpsertPackageInfo(c.ctx, data.UpsertPackageInfoParams{PackageID: chi, Version: "v5.2.0", Synopsis: "router", License: "MIT"})
+	if c.ok("UpsertPackageInfo existing", err) && (info.Version != "v5.2.0" || info.Synopsis != "router") {
+		c.errorf("UpsertPackageInfo on an existing package: got %+v", info)
+	}
+
+	if got, err := c.s.GetPackageInfo(c.ctx, chi); c.ok("GetPackageInfo", err) && got.Version != "v5.2.0" {
+		c.errorf("GetPackageInfo: got version %q, want v5.2.0", got.Version)
+	}
+	_, err = c.s.GetPackageInfo(c.ctx, -1)
+	c.noRows("GetPackageInfo missing", err)
+
+	c.ok("MarkDeleteByName", c.s.MarkDeleteByName(c.ctx, []string{"zerolog"}))
+	infos, err := c.s.ListPackageInfo(c.ctx)
+	if c.ok("ListPackageInfo", err) && (len(infos) != 1 || infos[0].PackageID != chi) {
+		c.errorf("ListPackageInfo: got %+v, want only chi", infos)
+	}
+}
+
+func (c *checker) deletion() {
+	c.ok("CleanDatabase", c.s.CleanDatabase(c.ctx))
+	if _, err := c.s.GetIDByName(c.ctx, "zerolog"); !errors.Is(err, sql.ErrNoRows) {
+		c.errorf("GetIDByName after CleanDatabase: got error %v, want sql.ErrNoRows", err)
+	}
+
+	c.ok("DeletePackagesByName", c.s.DeletePackagesByName(c.ctx, []string{"cobra-cli"}))
+	pkgs, err := c.s.ListPackagesByLastUsed(c.ctx, -1)
+	if c.ok("ListPackagesByLastUsed", err) && !slices.Equal(names(pkgs), []string{"chi"}) {
+		c.errorf("ListPackagesByLastUsed after deletion: got %v, want [chi]", names(pkgs))
+	}
+}
@@ -0,0 +1,240 @@
+// Package vulndb reads a local copy of the Go vulnerability database in
+// OSV format, as served by vuln.go.dev and accepted by govulncheck -db.
+package vulndb
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// Entry is the subset of an OSV entry used by gopk.
+type Entry struct {
+	ID       string     `json:"id"`
+	Summary  string     `json:"summary"`
+	Aliases  []string   `json:"aliases"`
+	Affected []Affected `json:"affected"`
+}
+
+type Affected struct {
+	Package struct {
+		Name      string `json:"name"`
+		Ecosystem string `json:"ecosystem"`
+	} `json:"package"`
+	Ranges []Range `json:"ranges"`
+}
+
+type Range struct {
+	Type   string  `json:"type"`
+	Events []Event `json:"events"`
+}
+
+type Event struct {
+	Introduced string `json:"introduced,omitempty"`
+	Fixed      string `json:"fixed,omitempty"`
+}
+
+// DB is an opened vulnerability database.
+type DB struct {
+	fsys    fs.FS
+	closer  func() error
+	modules map[string][]string
+	entries map[string]*Entry
+}
+
+// Open loads the database at name, which is either a directory or a zip
+// archive. Databases with an index/modules.json file are read lazily; a
+// plain directory of <ID>.json entries is indexed on open.
+func Open(name string) (*DB, error) {
+	info, err := os.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+
+	db := &DB{entries: make(map[string]*Entry), closer: func() error { return nil }}
+	if info.IsDir() {
+		db.fsys = os.DirFS(name)
+	} else {
+		zr, err := zip.OpenReader(name)
+		if err != nil {
+			return nil, fmt.Errorf("open vulnerability database: %w", err)
+		}
+		db.fsys, db.closer = zr, zr.Close
+		if sub, ok := singleDir(zr); ok {
+			db.fsys = sub
+		}
+	}
+
+	if err := db.loadIndex(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("open vulnerability database %s: %w", name, err)
+	}
+	return db, nil
+}
+
+func (db *DB) Close() error {
+	return db.closer()
+}
+
+func (db *DB) loadIndex() error {
+	b, err := fs.ReadFile(db.fsys, "index/modules.json")
+	if errors.Is(err, fs.ErrNotExist) {
+		return db.indexEntries()
+	}
+	if err != nil {
+		return err
+	}
+
+	var modules []struct {
+		Path  string `json:"path"`
+		Vulns []struct {
+			ID string `json:"id"`
+		} `json:"vulns"`
+	}
+	if err := json.Unmarshal(b, &modules); err != nil {
+		return err
+	}
+
+	db.modules = make(map[string][]string, len(modules))
+	for _, m := range modules {
+		for _, v := range m.Vulns {
+			db.modules[m.Path] = append(db.modules[m.Path], v.ID)
+		}
+	}
+	return nil
+}
+
+func (db *DB) indexEntries() error {
+	db.modules = make(map[string][]string)
+	return fs.WalkDir(db.fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || path.Ext(p) != ".json" {
+			return err
+		}
+		e, err := readEntry(db.fsys, p)
+		if err != nil {
+			return err
+		}
+		db.entries[e.ID] = e
+		for _, a := range e.Affected {
+			db.modules[a.Package.Name] = append(db.modules[a.Package.Name], e.ID)
+		}
+		return nil
+	})
+}
+
+func (db *DB) entry(id string) (*Entry, error) {
+	if e, ok := db.entries[id]; ok {
+		return e, nil
+	}
+	e, err := readEntry(db.fsys, "ID/"+id+".json")
+	if err != nil {
+		return nil, err
+	}
+	db.entries[id] = e
+	return e, nil
+}
+
+// Vulns returns the entries affecting version of the module at modulePath,
+// sorted by ID. Versions that are not canonical semantic versions, such as
+// "latest", cannot be matched and yield no entries.
+func (db *DB) Vulns(modulePath, version string) ([]*Entry, error) {
+	if !semver.IsValid(version) {
+		return nil, nil
+	}
+
+	var found []*Entry
+	for _, id := range db.modules[modulePath] {
+		e, err := db.entry(id)
+		if err != nil {
+			return nil, err
+		}
+		if e.affects(modulePath, version) {
+			found = append(found, e)
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool { return found[i].ID < found[j].ID })
+	return found, nil
+}
+
+func (e *Entry) affects(modulePath, version string) bool {
+	for _, a := range e.Affected {
+		if a.Package.Name != modulePath {
+			continue
+		}
+		if len(a.Ranges) == 0 {
+			return true
+		}
+		for _, r := range a.Ranges {
+			if r.Type == "SEMVER" && r.contains(version) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// contains walks the events in version order and reports whether version
+// ends up inside an introduced..fixed interval.
+func (r Range) contains(version string) bool {
+	events := append([]Event(nil), r.Events...)
+	sort.SliceStable(events, func(i, j int) bool {
+		return semver.Compare(canonical(events[i].version()), canonical(events[j].version())) < 0
+	})
+
+	affected := false
+	for _, ev := range events {
+		if semver.Compare(version, canonical(ev.version())) < 0 {
+			break
+		}
+		affected = ev.Introduced != ""
+	}
+	return affected
+}
+
+func (ev Event) version() string {
+	if ev.Introduced != "" {
+		return ev.Introduced
+	}
+	return ev.Fixed
+}
+
+// canonical converts OSV versions, which have no "v" prefix and use "0"
+// for the beginning of time, to Go semantic versions.
+func canonical(v string) string {
+	if v == "0" {
+		return "v0.0.0-0"
+	}
+	return "v" + strings.TrimPrefix(v, "v")
+}
+
+func readEntry(fsys fs.FS, name string) (*Entry, error) {
+	b, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	var e Entry
+	if err := json.Unmarshal(b, &e); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return &e, nil
+}
+
+// singleDir returns the only top-level directory of an archive that wraps
+// the database in a directory of its own.
+func singleDir(fsys fs.FS) (fs.FS, bool) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil || len(entries) != 1 || !entries[0].IsDir() || entries[0].Name() == "index" || entries[0].Name() == "ID" {
+		return nil, false
+	}
+	sub, err := fs.Sub(fsys, entries[0].Name())
+	return sub, err == nil
+}
@@ -0,0 +1,435 @@
+// Package gopk is the Go API of the gopk package registry.
+//
+// A Registry resolves aliases to Go import paths, saves new packages,
+// lists and groups them, installs them into the Go module in the current
+// directory and exports the registry in the formats read by 'gopk apply'.
+// It reads the same database, profiles, read-only layers and config file as
+// the gopk command.
+//
+// The add, alias add, alias rm, export, fork, get, group, install, list,
+// search and unreplace commands go through a Registry. The other commands
+// and the TUI still use gopk's internal packages for what the API does not
+// cover: templates, snippets, registry files, project diffs and deletion.
+//
+//	reg, err := gopk.Open()
+//	if err != nil {
+//		return err
+//	}
+//	defer reg.Close()
+//
+//	pkgs, err := reg.Resolve(ctx, "chi", "zerolog")
+package gopk
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/lewvy/gopk/config"
+	"github.com/lewvy/gopk/internal/data"
+	"github.com/lewvy/gopk/internal/service"
+	"github.com/lewvy/gopk/internal/store"
+)
+
+// PersonalLayer is the Layer of packages saved in the writable database.
+const PersonalLayer = service.PersonalLayer
+
+var (
+	// ErrNotFound is returned when an alias or group is not in the registry.
+	ErrNotFound = service.ErrNotFound
+	// ErrExists is returned by Add when the alias is taken and Force is not set.
+	ErrExists = service.ErrConstraintUnique
+	// ErrAliasTaken is returned by Add and AddAliases when an alias is
+	// already an alias of another package, which Force does not override.
+	ErrAliasTaken = service.ErrAliasTaken
+	// ErrPolicy is returned by Install when the license policy blocks a package.
+	ErrPolicy = service.ErrPolicy
+	// ErrVulnerable is returned by Install with FailOnVuln when a saved
+	// version has known vulnerabilities.
+	ErrVulnerable = service.ErrVulnerable
+)
+
+// Package is a saved package.
+type Package struct {
+	ID         int64     `json:"id"`
+	Name       string    `json:"name"`
+	URL        string    `json:"url"`
+	Module     string    `json:"module"`
+	ImportName string    `json:"import_name,omitempty"`
+	Version    string    `json:"version"`
+	Freq       int64     `json:"freq"`
+	LastUsed   time.Time `json:"last_used,omitzero"`
+
+	// Kind is "library", "tool" or "both".
+	Kind string `json:"kind"`
+
+	// Aliases are the extra aliases the package also resolves from, set
+	// with AddAliases.
+	Aliases []string `json:"aliases,omitempty"`
+
+	// Replace is the local directory or fork module query that replaces
+	// the module on install, set with SetReplace. ReplaceAlways applies
+	// it to every install rather than only with InstallOptions.Local.
+	Replace       string `json:"replace,omitempty"`
+	ReplaceAlways bool   `json:"replace_always,omitempty"`
+
+	// Synopsis and License are recorded by 'gopk enrich'.
+	Synopsis string `json:"synopsis,omitempty"`
+	License  string `json:"license,omitempty"`
+
+	// Layer is the name of the read-only layer the package comes from, or
+	// PersonalLayer.
+	Layer string `json:"layer"`
+}
+
+// Group is a named set of packages.
+type Group struct {
+	Name     string   `json:"name"`
+	Packages []string `json:"packages"`
+}
+
+// Registry is a handle on a gopk registry. It is safe for use by one
+// goroutine at a time.
+type Registry struct {
+	s store.Store
+	q data.Querier
+}
+
+// Open opens the registry of the active profile with the storage backend
+// set in the config file, creating and migrating the database if needed.
+func Open() (*Registry, error) {
+	s, err := store.Open()
+	if err != nil {
+		return nil, err
+	}
+	return NewStore(s), nil
+}
+
+// New returns a Registry backed by an open, migrated SQLite database.
+func New(db *sql.DB) *Registry {
+	return NewStore(store.NewSQLite(db))
+}
+
+// NewStore returns a Registry backed by s.
+func NewStore(s store.Store) *Registry {
+	return &Registry{s: s, q: s}
+}
+
+// Close closes the underlying store.
+func (r *Registry) Close() error {
+	return r.s.Close()
+}
+
+// AddOptions describe a package to save. Name, Module and Version are
+// inferred or defaulted when empty.
+type AddOptions struct {
+	Name       string
+	Module     string
+	ImportName string
+	Version    string
+
+	// Kind is "library" (the default), "tool" or "both". Tools are added
+	// to modules with 'go get -tool' and can be installed with
+	// InstallTools.
+	Kind string
+
+	// Force moves Name to url when it is saved for another import path.
+	// Inferred aliases never take over another package: when the
+	// inferred alias is taken, an owner-qualified one is used instead.
+	Force bool
+}
+
+// Add saves the package at url, an import path with an optional scheme.
+func (r *Registry) Add(ctx context.Context, url string, opts AddOptions) (Package, error) {
+	if opts.Version == "" {
+		opts.Version = "latest"
+	}
+	pkg, err := service.Add(ctx, r.q, service.AddParams{
+		URL:        url,
+		Module:     opts.Module,
+		Name:       opts.Name,
+		ImportName: opts.ImportName,
+		Version:    opts.Version,
+		Kind:       opts.Kind,
+		Force:      opts.Force,
+	})
+	if err != nil {
+		return Package{}, err
+	}
+	return toPackage(pkg, nil), nil
+}
+
+// Resolve returns the packages saved under aliases, in order. Aliases,
+// primary or extra, are looked up in the personal database first, then in
+// the configured layers.
+// If any alias is missing, the error wraps ErrNotFound.
+func (r *Registry) Resolve(ctx context.Context, aliases ...string) ([]Package, error) {
+	found, missing, err := service.Resolve(ctx, r.q, aliases)
+	if err != nil {
+		return nil, err
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, strings.Join(missing, ", "))
+	}
+	return r.packages(ctx, found)
+}
+
+// ProposeAlias returns the alias Add would save url under without a
+// Name, following the [alias] section of the config file. If the alias
+// is taken and the config refuses to qualify it, the error wraps
+// ErrAliasTaken and names the package that owns it.
+func (r *Registry) ProposeAlias(ctx context.Context, url string) (string, error) {
+	p, err := service.InferAlias(ctx, r.q, url)
+	if err != nil {
+		return "", err
+	}
+	return p.Alias, nil
+}
+
+// AddAliases gives the package saved as alias the extra aliases names and
+// returns the ones it did not already answer to. If a name is already an
+// alias of another package, nothing is added and the error wraps
+// ErrAliasTaken and names that package.
+func (r *Registry) AddAliases(ctx context.Context, alias string, names ...string) ([]string, error) {
+	return service.AddAliases(ctx, r.q, alias, names)
+}
+
+// RemoveAliases removes extra aliases added with AddAliases.
+func (r *Registry) RemoveAliases(ctx context.Context, names ...string) error {
+	return service.RemoveAliases(ctx, r.q, names)
+}
+
+// ListOptions control List.
+type ListOptions struct {
+	// Limit caps the number of packages returned; zero means no limit.
+	Limit int
+	// ByFrequency sorts by use count instead of last use.
+	ByFrequency bool
+	// Group restricts the list to the members of a group.
+	Group string
+}
+
+// List returns the saved packages, followed by those of the configured
+// layers that are not shadowed by a personal alias.
+func (r *Registry) List(ctx context.Context, opts ListOptions) ([]Package, error) {
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+
+	var pkgs []data.Package
+	var err error
+	switch {
+	case opts.Group != "" && opts.ByFrequency:
+		pkgs, err = service.ListPackagesByGroupOrderByFreq(ctx, r.q, opts.Group)
+	case opts.Group != "":
+		pkgs, err = service.ListPackagesByGroupOrderByLU(ctx, r.q, opts.Group)
+	default:
+		pkgs, err = service.List(ctx, r.q, limit, opts.ByFrequency)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if limit >= 0 && len(pkgs) > limit {
+		pkgs = pkgs[:limit]
+	}
+	return r.packages(ctx, pkgs)
+}
+
+// Search returns the saved packages matching query: fuzzily on the alias
+// and URL, best match first, then by the words of the synopsis.
+func (r *Registry) Search(ctx context.Context, query string) ([]Package, error) {
+	pkgs, err := service.Search(ctx, r.q, query)
+	if err != nil {
+		return nil, err
+	}
+	return r.packages(ctx, pkgs)
+}
+
+// Groups returns every group with the aliases of its members.
+func (r *Registry) Groups(ctx context.Context) ([]Group, error) {
+	groups, err := service.ListGroups(ctx, r.q)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]Group, 0, len(groups))
+	for _, g := range groups {
+		members, err := r.q.ListPackagesByGroup(ctx, g.Name)
+		if err != nil {
+			return nil, err
+		}
+		group := Group{Name: g.Name, Packages: make([]string, 0, len(members))}
+		for _, m := range members {
+			group.Packages = append(group.Packages, m.Name)
+		}
+		out = append(out, group)
+	}
+	return out, nil
+}
+
+// InstallOptions control Install and InstallGroup.
+type InstallOptions struct {
+	// FailOnVuln refuses to install saved versions with known
+	// vulnerabilities instead of warning about them.
+	FailOnVuln bool
+
+	// Local adds replace directives for the packages that have a saved
+	// replacement before fetching them.
+	Local bool
+
+	// Fuzzy resolves an alias that is not saved to the only package it
+	// matches in a Search.
+	Fuzzy bool
+}
+
+func (o InstallOptions) service() service.InstallOptions {
+	return service.InstallOptions{FailOnVuln: o.FailOnVuln, Local: o.Local, Fuzzy: o.Fuzzy}
+}
+
+// Install runs 'go get' for the packages saved under aliases in the Go
+// module of the current directory, with -tool for tools, after checking
+// them against the license policy and vulnerability database. Packages
+// that are found are installed even if some aliases are missing; the
+// error then wraps ErrNotFound.
+func (r *Registry) Install(ctx context.Context, aliases []string, opts InstallOptions) error {
+	return service.GetFromName(ctx, r.q, aliases, opts.service())
+}
+
+// InstallTools runs 'go install' for the tools saved under aliases, which
+// puts their binaries in GOBIN outside of any module. Every package must be
+// of kind "tool" or "both".
+func (r *Registry) InstallTools(ctx context.Context, aliases []string, opts InstallOptions) error {
+	return service.InstallFromName(ctx, r.q, aliases, opts.service())
+}
+
+// InstallGroup installs every member of a group, as Install does.
+func (r *Registry) InstallGroup(ctx context.Context, group string, opts InstallOptions) error {
+	return service.InstallGroup(ctx, r.q, group, opts.service())
+}
+
+// SetReplace saves the replacement for the package saved under alias:
+// a local directory or a fork module query with a version, such as
+// "github.com/me/zap@v1.27.0". An empty target clears it. With always,
+// Install applies it without InstallOptions.Local.
+func (r *Registry) SetReplace(ctx context.Context, alias, target string, always bool) (Package, error) {
+	pkg, err := service.SetReplace(ctx, r.q, alias, target, always)
+	if err != nil {
+		return Package{}, err
+	}
+	return toPackage(pkg, nil), nil
+}
+
+// Unreplace drops the replace directives for the packages saved under
+// aliases from the go.mod of the current directory and fetches the
+// upstream modules at their saved versions.
+func (r *Registry) Unreplace(ctx context.Context, aliases []string) error {
+	return service.Unreplace(ctx, r.q, aliases)
+}
+
+// ExportOptions control Export.
+type ExportOptions struct {
+	// Format is "json", "yaml" or "toml". It defaults to "toml".
+	Format string
+	// Group exports a single group and its packages.
+	Group string
+}
+
+// Export writes the packages, snippets and groups of the personal
+// database to w.
+func (r *Registry) Export(ctx context.Context, w io.Writer, opts ExportOptions) error {
+	if opts.Format == "" {
+		opts.Format = "toml"
+	}
+	f, err := service.Export(ctx, r.q, opts.Group)
+	if err != nil {
+		return err
+	}
+	content, err := f.Encode(opts.Format)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(content)
+	return err
+}
+
+// Vulns matches the saved versions of pkgs against the configured
+// vulnerability database and returns the IDs of the entries affecting each
+// package, keyed by package ID. It returns an empty map when no database is
+// configured or no package is affected.
+func (r *Registry) Vulns(ctx context.Context, pkgs []Package) (map[int64][]string, error) {
+	out := make(map[int64][]string)
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	db, err := service.OpenVulnDB(cfg)
+	if err != nil || db == nil {
+		return out, err
+	}
+	defer db.Close()
+
+	rows := make([]data.Package, len(pkgs))
+	for i, p := range pkgs {
+		rows[i] = data.Package{
+			ID:      p.ID,
+			Url:     p.URL,
+			Module:  p.Module,
+			Version: sql.NullString{Valid: true, String: p.Version},
+		}
+	}
+	vulns, err := service.PackageVulns(db, rows)
+	if err != nil {
+		return nil, err
+	}
+	for id, entries := range vulns {
+		for _, e := range entries {
+			out[id] = append(out[id], e.ID)
+		}
+	}
+	return out, nil
+}
+
+func (r *Registry) packages(ctx context.Context, pkgs []data.Package) ([]Package, error) {
+	layers, err := service.ConfiguredLayers()
+	if err != nil {
+		return nil, err
+	}
+	info, err := service.PackageInfo(ctx, r.q)
+	if err != nil {
+		return nil, err
+	}
+	aliases, err := service.AliasesByPackage(ctx, r.q)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Package, len(pkgs))
+	for i, p := range pkgs {
+		out[i] = toPackage(p, layers)
+		out[i].Aliases = aliases[p.ID]
+		out[i].Synopsis = info[p.ID].Synopsis
+		out[i].License = info[p.ID].License
+	}
+	return out, nil
+}
+
+func toPackage(p data.Package, layers service.Layers) Package {
+	return Package{
+		ID:            p.ID,
+		Name:          p.Name,
+		URL:           p.Url,
+		Module:        service.ModulePath(p),
+		ImportName:    p.ImportName,
+		Version:       p.Version.String,
+		Freq:          p.Freq.Int64,
+		LastUsed:      p.LastUsed.Time,
+		Kind:          service.KindOf(p),
+		Replace:       p.ReplaceWith,
+		ReplaceAlways: p.ReplaceAlways,
+		Layer:         layers.Of(p),
+	}
+}
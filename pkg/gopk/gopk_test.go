@@ -0,0 +1,291 @@
+package gopk_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/lewvy/gopk/config"
+	"github.com/lewvy/gopk/internal/gotool"
+	"github.com/lewvy/gopk/internal/service"
+	"github.com/lewvy/gopk/internal/store"
+	"github.com/lewvy/gopk/pkg/gopk"
+)
+
+// setup points the config and data directories at temporary ones and
+// installs fake as the go toolchain for the duration of the test.
+func setup(t *testing.T, fake *gotool.Fake) {
+	t.Helper()
+	t.Setenv("GOPK_CONFIG_DIR", t.TempDir())
+	t.Setenv("GOPK_DB_DIR", t.TempDir())
+	t.Setenv("GOPK_PROFILE", "")
+	t.Setenv("GOPK_STORAGE", "")
+
+	prev := service.Toolchain
+	service.Toolchain = fake
+	t.Cleanup(func() { service.Toolchain = prev })
+}
+
+// newRegistry returns a Registry on an empty JSON store, along with the
+// store for setting up state the API does not cover.
+func newRegistry(t *testing.T) (*gopk.Registry, store.Store) {
+	t.Helper()
+	setup(t, &gotool.Fake{})
+	s, err := store.OpenJSON(filepath.Join(t.TempDir(), "packages.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg := gopk.NewStore(s)
+	t.Cleanup(func() { reg.Close() })
+	return reg, s
+}
+
+func add(t *testing.T, reg *gopk.Registry, url string, opts gopk.AddOptions) gopk.Package {
+	t.Helper()
+	pkg, err := reg.Add(context.Background(), url, opts)
+	if err != nil {
+		t.Fatalf("Add %s: %v", url, err)
+	}
+	return pkg
+}
+
+func names(pkgs []gopk.Package) []string {
+	out := make([]string, len(pkgs))
+	for i, p := range pkgs {
+		out[i] = p.Name
+	}
+	return out
+}
+
+func TestOpen(t *testing.T) {
+	setup(t, &gotool.Fake{})
+	ctx := context.Background()
+
+	reg, err := gopk.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	add(t, reg, "github.com/go-chi/chi/v5", gopk.AddOptions{})
+	if err := reg.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reg, err = gopk.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reg.Close()
+	pkgs, err := reg.Resolve(ctx, "chi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pkgs[0].URL != "github.com/go-chi/chi/v5" {
+		t.Errorf("Resolve after reopening: got %+v", pkgs[0])
+	}
+}
+
+func TestNew(t *testing.T) {
+	setup(t, &gotool.Fake{})
+	db, err := config.InitDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg := gopk.New(db)
+	defer reg.Close()
+
+	add(t, reg, "github.com/rs/zerolog", gopk.AddOptions{Version: "v1.33.0"})
+	pkgs, err := reg.Resolve(context.Background(), "zerolog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pkgs[0].Version != "v1.33.0" || pkgs[0].Layer != gopk.PersonalLayer {
+		t.Errorf("Resolve: got %+v", pkgs[0])
+	}
+}
+
+func TestAdd(t *testing.T) {
+	reg, _ := newRegistry(t)
+	ctx := context.Background()
+
+	pkg := add(t, reg, "https://github.com/mattn/go-sqlite3", gopk.AddOptions{})
+	if pkg.Name != "sqlite3" || pkg.URL != "github.com/mattn/go-sqlite3" ||
+		pkg.Module != "github.com/mattn/go-sqlite3" || pkg.Version != "latest" || pkg.Kind != "library" {
+		t.Errorf("Add with defaults: got %+v", pkg)
+	}
+
+	_, err := reg.Add(ctx, "modernc.org/sqlite", gopk.AddOptions{Name: "sqlite3"})
+	if !errors.Is(err, gopk.ErrExists) {
+		t.Errorf("Add with a taken alias: got error %v, want ErrExists", err)
+	}
+	moved := add(t, reg, "modernc.org/sqlite", gopk.AddOptions{Name: "sqlite3", Force: true})
+	if moved.ID != pkg.ID || moved.URL != "modernc.org/sqlite" {
+		t.Errorf("Add with Force: got %+v, want %s moved", moved, pkg.Name)
+	}
+
+	if _, err := reg.AddAliases(ctx, "sqlite3", "db"); err != nil {
+		t.Fatal(err)
+	}
+	_, err = reg.Add(ctx, "github.com/jmoiron/sqlx", gopk.AddOptions{Name: "db", Force: true})
+	if !errors.Is(err, gopk.ErrAliasTaken) {
+		t.Errorf("Add with an extra alias of another package: got error %v, want ErrAliasTaken", err)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	reg, _ := newRegistry(t)
+	ctx := context.Background()
+	add(t, reg, "github.com/go-chi/chi/v5", gopk.AddOptions{})
+	add(t, reg, "go.uber.org/zap", gopk.AddOptions{})
+	if _, err := reg.AddAliases(ctx, "zap", "log"); err != nil {
+		t.Fatal(err)
+	}
+
+	pkgs, err := reg.Resolve(ctx, "log", "chi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(pkgs); !slices.Equal(got, []string{"zap", "chi"}) {
+		t.Errorf("Resolve: got %v, want [zap chi]", got)
+	}
+	if !slices.Equal(pkgs[0].Aliases, []string{"log"}) {
+		t.Errorf("Resolve: got aliases %v, want [log]", pkgs[0].Aliases)
+	}
+
+	if _, err := reg.Resolve(ctx, "chi", "missing"); !errors.Is(err, gopk.ErrNotFound) {
+		t.Errorf("Resolve with a missing alias: got error %v, want ErrNotFound", err)
+	}
+}
+
+func TestList(t *testing.T) {
+	reg, s := newRegistry(t)
+	ctx := context.Background()
+	for _, url := range []string{"github.com/go-chi/chi/v5", "github.com/spf13/cobra", "github.com/rs/zerolog"} {
+		add(t, reg, url, gopk.AddOptions{})
+	}
+	for range 2 {
+		if err := s.UpdatePackageUsage(ctx, "github.com/spf13/cobra"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pkgs, err := reg.List(ctx, gopk.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pkgs) != 3 {
+		t.Errorf("List: got %v, want 3 packages", names(pkgs))
+	}
+
+	pkgs, err = reg.List(ctx, gopk.ListOptions{Limit: 1, ByFrequency: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(pkgs); !slices.Equal(got, []string{"cobra"}) {
+		t.Errorf("List by frequency with Limit 1: got %v, want [cobra]", got)
+	}
+
+	if err := service.CreateGroup(s, "web"); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.AssignToGroup(s, []string{"github.com/go-chi/chi/v5", "github.com/rs/zerolog"}, "web"); err != nil {
+		t.Fatal(err)
+	}
+	pkgs, err = reg.List(ctx, gopk.ListOptions{Group: "web", Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pkgs) != 1 || !slices.Contains([]string{"chi", "zerolog"}, pkgs[0].Name) {
+		t.Errorf("List of a group with Limit 1: got %v", names(pkgs))
+	}
+}
+
+func TestGroups(t *testing.T) {
+	reg, s := newRegistry(t)
+	ctx := context.Background()
+	add(t, reg, "github.com/go-chi/chi/v5", gopk.AddOptions{})
+	add(t, reg, "github.com/rs/zerolog", gopk.AddOptions{})
+	for _, g := range []string{"web", "empty"} {
+		if err := service.CreateGroup(s, g); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := service.AssignToGroup(s, []string{"github.com/go-chi/chi/v5", "github.com/rs/zerolog"}, "web"); err != nil {
+		t.Fatal(err)
+	}
+
+	groups, err := reg.Groups(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 2 || groups[0].Name != "empty" || len(groups[0].Packages) != 0 ||
+		groups[1].Name != "web" || !slices.Equal(groups[1].Packages, []string{"chi", "zerolog"}) {
+		t.Errorf("Groups: got %+v", groups)
+	}
+}
+
+func TestInstall(t *testing.T) {
+	reg, _ := newRegistry(t)
+	fake := &gotool.Fake{}
+	service.Toolchain = fake
+	ctx := context.Background()
+	add(t, reg, "github.com/go-chi/chi/v5", gopk.AddOptions{Version: "v5.0.12"})
+	add(t, reg, "golang.org/x/tools/cmd/stringer", gopk.AddOptions{Kind: "tool"})
+
+	err := reg.Install(ctx, []string{"chi", "stringer", "missing"}, gopk.InstallOptions{})
+	if !errors.Is(err, gopk.ErrNotFound) {
+		t.Errorf("Install with a missing alias: got error %v, want ErrNotFound", err)
+	}
+	var got []string
+	for _, call := range fake.Calls() {
+		got = append(got, call.String())
+	}
+	want := []string{
+		"go get github.com/go-chi/chi/v5@v5.0.12",
+		"go get -tool golang.org/x/tools/cmd/stringer@latest",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Install: got calls %q, want %q", got, want)
+	}
+}
+
+func TestExport(t *testing.T) {
+	reg, s := newRegistry(t)
+	ctx := context.Background()
+	add(t, reg, "github.com/go-chi/chi/v5", gopk.AddOptions{Version: "v5.0.12"})
+	add(t, reg, "github.com/rs/zerolog", gopk.AddOptions{})
+	if err := service.CreateGroup(s, "web"); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.AssignToGroup(s, []string{"github.com/go-chi/chi/v5"}, "web"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, format := range []string{"toml", "json", "yaml"} {
+		var buf bytes.Buffer
+		if err := reg.Export(ctx, &buf, gopk.ExportOptions{Format: format}); err != nil {
+			t.Fatalf("Export %s: %v", format, err)
+		}
+		f, err := service.DecodeRegistryFile(buf.Bytes(), format)
+		if err != nil {
+			t.Fatalf("Export %s: %v", format, err)
+		}
+		if len(f.Packages) != 2 || len(f.Groups) != 1 || !slices.Equal(f.Groups[0].Packages, []string{"chi"}) {
+			t.Errorf("Export %s: got %+v", format, f)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := reg.Export(ctx, &buf, gopk.ExportOptions{Group: "web"}); err != nil {
+		t.Fatal(err)
+	}
+	f, err := service.DecodeRegistryFile(buf.Bytes(), "toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Packages) != 1 || f.Packages[0].Name != "chi" || f.Packages[0].Version != "v5.0.12" {
+		t.Errorf("Export of a group: got %+v", f)
+	}
+}
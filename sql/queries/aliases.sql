@@ -0,0 +1,29 @@
+-- name: AddPackageAlias :one
+INSERT INTO package_aliases (name, package_id)
+VALUES (?, ?)
+RETURNING *;
+
+-- name: GetPackageAlias :one
+SELECT *
+FROM package_aliases
+WHERE name = ?;
+
+-- name: GetPackageAliasesByNames :many
+SELECT *
+FROM package_aliases
+WHERE name IN (sqlc.slice('names'));
+
+-- name: ListPackageAliases :many
+SELECT *
+FROM package_aliases
+ORDER BY name ASC;
+
+-- name: ListAliasesByPackage :many
+SELECT *
+FROM package_aliases
+WHERE package_id = ?
+ORDER BY name ASC;
+
+-- name: DeletePackageAlias :execrows
+DELETE FROM package_aliases
+WHERE name = ?;
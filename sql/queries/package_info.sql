@@ -0,0 +1,23 @@
+-- name: UpsertPackageInfo :one
+INSERT INTO package_info (package_id, version, go_version, deps, synopsis, license_file, license)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (package_id) DO UPDATE
+SET version = excluded.version,
+    go_version = excluded.go_version,
+    deps = excluded.deps,
+    synopsis = excluded.synopsis,
+    license_file = excluded.license_file,
+    license = excluded.license,
+    enriched_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: GetPackageInfo :one
+SELECT *
+FROM package_info
+WHERE package_id = ?;
+
+-- name: ListPackageInfo :many
+SELECT i.*
+FROM package_info i
+JOIN packages p ON p.id = i.package_id
+WHERE p.is_deleted = false;
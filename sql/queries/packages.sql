@@ -1,8 +1,8 @@
 -- name: AddPackageWithVersion :one
-INSERT INTO packages (name, url, version) 
-VALUES (?, ?, ?)
+INSERT INTO packages (name, url, module, import_name, version, kind) 
+VALUES (?, ?, ?, ?, ?, ?)
 ON CONFLICT (name) DO UPDATE 
-SET is_deleted = false, url = excluded.url, version = excluded.version
+SET is_deleted = false, url = excluded.url, module = excluded.module, import_name = excluded.import_name, version = excluded.version, kind = excluded.kind
 RETURNING *;
 
 -- name: GetIDByName :one
@@ -15,18 +15,19 @@ WHERE url = ?;
 
 -- name: UpdatePackageByName :one
 UPDATE packages
-SET url = ?, version = ?
+SET url = ?, module = ?, import_name = ?, version = ?, kind = ?
 WHERE name = ?
 RETURNING *;
 
 -- name: GetURLsByNames :many
-SELECT name, url, version 
-FROM packages 
-WHERE name IN (sqlc.slice('names'));
+SELECT DISTINCT packages.*
+FROM packages
+JOIN package_names ON package_names.package_id = packages.id
+WHERE package_names.name IN (sqlc.slice('names'));
 
 -- name: UpdatePackage :one
 UPDATE packages
-SET name = ?, url = ?, version = ?
+SET name = ?, url = ?, module = ?, import_name = ?, version = ?, kind = ?
 WHERE id = ?
 RETURNING *;
 
@@ -72,3 +73,9 @@ SELECT * FROM packages WHERE id = ? and is_deleted = false;
 
 -- name: GetPackageByName :one
 SELECT * FROM packages WHERE name =? and is_deleted = false;
+
+-- name: SetPackageReplace :one
+UPDATE packages
+SET replace_with = ?, replace_always = ?, updated_at = CURRENT_TIMESTAMP
+WHERE name = ? AND is_deleted = false
+RETURNING *;
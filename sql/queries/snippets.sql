@@ -0,0 +1,21 @@
+-- name: UpsertSnippet :one
+INSERT INTO snippets (package_id, name, body)
+VALUES (?, ?, ?)
+ON CONFLICT (package_id, name) DO UPDATE
+SET body = excluded.body, updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: ListSnippetsByPackage :many
+SELECT *
+FROM snippets
+WHERE package_id = ?
+ORDER BY name ASC;
+
+-- name: GetSnippet :one
+SELECT *
+FROM snippets
+WHERE package_id = ? AND name = ?;
+
+-- name: DeleteSnippet :execrows
+DELETE FROM snippets
+WHERE package_id = ? AND name = ?;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE packages ADD COLUMN module TEXT NOT NULL DEFAULT '';
+UPDATE packages SET module = url WHERE module = '';
+CREATE INDEX idx_packages_module ON packages(module);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_packages_module;
+ALTER TABLE packages DROP COLUMN module;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE package_info (
+    package_id    INTEGER PRIMARY KEY,
+    version       TEXT NOT NULL,
+    go_version    TEXT NOT NULL DEFAULT '',
+    deps          INTEGER NOT NULL DEFAULT 0,
+    synopsis      TEXT NOT NULL DEFAULT '',
+    license_file  TEXT NOT NULL DEFAULT '',
+    enriched_at   TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (package_id)
+        REFERENCES packages(id)
+        ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS package_info;
+-- +goose StatementEnd
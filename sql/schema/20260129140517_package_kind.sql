@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE packages ADD COLUMN kind TEXT NOT NULL DEFAULT 'library';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE packages DROP COLUMN kind;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE packages ADD COLUMN replace_with TEXT NOT NULL DEFAULT '';
+ALTER TABLE packages ADD COLUMN replace_always BOOLEAN NOT NULL DEFAULT false;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE packages DROP COLUMN replace_always;
+ALTER TABLE packages DROP COLUMN replace_with;
+-- +goose StatementEnd
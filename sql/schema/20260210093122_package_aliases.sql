@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE package_aliases (
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    name        TEXT NOT NULL UNIQUE,
+    package_id  INTEGER NOT NULL,
+    created_at  TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    FOREIGN KEY (package_id)
+        REFERENCES packages(id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX idx_package_aliases_package ON package_aliases(package_id);
+
+-- package_names lists every name a package answers to: its primary
+-- alias and its extra aliases.
+CREATE VIEW package_names AS
+SELECT id AS package_id, name FROM packages
+UNION ALL
+SELECT package_id, name FROM package_aliases;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP VIEW IF EXISTS package_names;
+DROP TABLE IF EXISTS package_aliases;
+-- +goose StatementEnd